@@ -25,6 +25,8 @@ When called with a type prefix, installs that specific component type.
 
 Use "new" to trigger Smart Add: searches local templates, VoltAgent,
 and aitmpl.com using Claude CLI, then lets you pick and install.
+Without the claude CLI, or with --offline, local templates and cached
+external catalogs are ranked by a built-in search index instead.
 
 Examples:
   ck add                                  # Interactive agent picker
@@ -34,13 +36,41 @@ Examples:
   ck add command review                   # Add a specific command
   ck add rule testing                     # Add a specific rule
   ck add new database review              # Smart add — AI finds matching components
-  ck add new performance auditing         # Smart add — natural language query`,
+  ck add new performance auditing         # Smart add — natural language query
+  ck add new --offline database review    # Smart add ranked locally, no Claude or network
+  ck add --update                         # Update all externally installed components
+  ck add --update skill pdf               # Update one external skill`,
 	RunE: runAdd,
 }
 
+var (
+	addOffline     bool
+	addRecommender string
+	addCatalogs    []string
+	addTrust       bool
+	addUpdate      bool
+)
+
+func init() {
+	addCmd.Long += "\n\n" + componentTypesHelp()
+	addCmd.Flags().BoolVar(&addOffline, "offline", false, "Smart add: rank local and cached catalog components without Claude or network")
+	addCmd.Flags().StringVar(&addRecommender, "recommender", "", "Smart add backend: claude or http (default $BMAD_RECOMMENDER, else claude)")
+	addCmd.Flags().StringArrayVar(&addCatalogs, "catalog", nil, "Smart add: extra external catalog as type=location (git, json, voltagent, aitmpl); repeatable")
+	addCmd.Flags().BoolVar(&addTrust, "trust", false, "Install external components (smart add or --update) without reviewing them")
+	addCmd.Flags().BoolVar(&addUpdate, "update", false, "Update externally installed components to the latest commit of their ref, showing the diff")
+}
+
 func runAdd(cmd *cobra.Command, args []string) error {
 	tmplDir := resolveTemplateDir()
 	targetDir := resolveTarget()
+	setupTransforms(true)
+
+	// --update → re-fetch external components: ck add --update [type names...]
+	if addUpdate {
+		err := runUpdate(targetDir, args)
+		refreshClaudeMD(tmplDir, targetDir)
+		return err
+	}
 
 	// No args → interactive agent picker
 	if len(args) == 0 {
@@ -73,11 +103,8 @@ func runAdd(cmd *cobra.Command, args []string) error {
 }
 
 func isComponentType(t string) bool {
-	switch t {
-	case "agents", "skills", "commands", "rules":
-		return true
-	}
-	return false
+	_, ok := catalog.LookupKind(t)
+	return ok
 }
 
 // runInteractiveAdd shows a multi-select of available agents.
@@ -152,6 +179,7 @@ func runInteractiveAdd(tmplDir, targetDir string) error {
 	for _, name := range selected {
 		installAgent(tmplDir, targetDir, name)
 	}
+	refreshClaudeMD(tmplDir, targetDir)
 
 	fmt.Println()
 	fmt.Println(successStyle.Render(fmt.Sprintf("  %s Done!", arrow)))
@@ -167,6 +195,7 @@ func addAgents(tmplDir, targetDir string, names []string) error {
 	for _, name := range names {
 		installAgent(tmplDir, targetDir, name)
 	}
+	refreshClaudeMD(tmplDir, targetDir)
 
 	fmt.Println()
 	return nil
@@ -194,6 +223,7 @@ func addExplicitComponents(tmplDir, targetDir, compType string, names []string)
 			pullAgentDeps(tmplDir, targetDir, name)
 		}
 	}
+	refreshClaudeMD(tmplDir, targetDir)
 
 	fmt.Println()
 	return nil
@@ -334,6 +364,7 @@ func addBmadBundle(tmplDir, targetDir string) error {
 		}
 		fmt.Println(fmt.Sprintf("  %s %s", checkMark, infoStyle.Render(fmt.Sprintf("Added rule: %s", name))))
 	}
+	refreshClaudeMD(tmplDir, targetDir)
 
 	fmt.Println()
 	fmt.Println(successStyle.Render(fmt.Sprintf("  %s BMAD methodology installed!", arrow)))
@@ -420,25 +451,40 @@ func guessRulesForAgent(agentName string) []string {
 	}
 }
 
+// refreshClaudeMD recomposes the managed sections of CLAUDE.md after
+// components were installed or removed. A missing CLAUDE.md is left alone.
+func refreshClaudeMD(tmplDir, targetDir string) {
+	if _, err := os.Stat(filepath.Join(targetDir, catalog.ClaudeMDFile)); err != nil {
+		return
+	}
+	if err := catalog.ComposeClaudeMD(tmplDir, targetDir); err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  Updating CLAUDE.md: %v", err)))
+	}
+}
+
 // ensureBaseFiles copies CLAUDE.md + settings.json if they don't exist.
 func ensureBaseFiles(tmplDir, targetDir string) {
-	claudeMd := filepath.Join(targetDir, "CLAUDE.md")
+	claudeMd := filepath.Join(targetDir, catalog.ClaudeMDFile)
 	if _, err := os.Stat(claudeMd); os.IsNotExist(err) {
 		_ = catalog.CopyBaseFiles(tmplDir, targetDir)
 	}
 }
 
+// normalizeType maps a singular or plural kind alias to the kind's name.
+// Unknown values are returned lowercased.
 func normalizeType(t string) string {
-	t = strings.ToLower(t)
-	switch t {
-	case "agent":
-		return "agents"
-	case "skill":
-		return "skills"
-	case "command":
-		return "commands"
-	case "rule":
-		return "rules"
-	}
-	return t
+	if k, ok := catalog.LookupKind(t); ok {
+		return k.Name()
+	}
+	return strings.ToLower(t)
+}
+
+// componentTypesHelp renders the list of registered kinds for command help.
+func componentTypesHelp() string {
+	var sb strings.Builder
+	sb.WriteString("Component types:\n")
+	for _, k := range catalog.Kinds() {
+		sb.WriteString(fmt.Sprintf("  %-10s %-10s %s\n", k.Singular(), k.Name(), k.Describe()))
+	}
+	return sb.String()
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/provider"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the external catalog cache",
+	Long: `Manage the cache of external catalogs used by smart add (ck add new).
+
+Catalogs are cached under ~/.bmad/cache/catalogs/ and reused for
+$BMAD_CATALOG_TTL (default 24h). After that they are revalidated with
+ETag/Last-Modified when the server supports it. When a catalog cannot be
+fetched, smart add uses the cached copy whatever its age.`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached catalogs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheList()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [names...]",
+	Short: "Remove cached catalogs (all when no names are given)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheClear(args)
+	},
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh [names...]",
+	Short: "Re-fetch configured catalogs, ignoring the TTL",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheRefresh(args)
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheRefreshCmd)
+}
+
+func runCacheList() error {
+	cache, err := provider.NewCache()
+	if err != nil {
+		return err
+	}
+	records, err := cache.List()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(sectionHeader("CATALOG CACHE"))
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  %s (TTL %s)", cache.Dir, cache.TTL)))
+	fmt.Println()
+	if len(records) == 0 {
+		fmt.Println(dimStyle.Render("  No cached catalogs."))
+		return nil
+	}
+
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		mark, state := checkMark, infoStyle.Render("fresh")
+		if !cache.Fresh(r) {
+			mark, state = warnStyle.Render("!"), warnStyle.Render("stale")
+		}
+		rows = append(rows, []string{
+			mark,
+			lipgloss.NewStyle().Foreground(white).Bold(true).Render(r.Name),
+			dimStyle.Render(r.Type),
+			fmt.Sprintf("%d", len(r.Entries)),
+			provider.FormatAge(r.Age()),
+			state,
+			dimStyle.Render(formatSize(r.Size)),
+		})
+	}
+
+	tbl := table.New().
+		Border(lipgloss.HiddenBorder()).
+		Headers(
+			"",
+			tableHeaderStyle.Render("Name"),
+			tableHeaderStyle.Render("Type"),
+			tableHeaderStyle.Render("Entries"),
+			tableHeaderStyle.Render("Fetched"),
+			tableHeaderStyle.Render("State"),
+			tableHeaderStyle.Render("Size"),
+		).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			s := lipgloss.NewStyle().PaddingRight(2)
+			if col == 0 {
+				s = s.PaddingLeft(4).Width(3)
+			}
+			return s
+		})
+
+	fmt.Println(tbl)
+	return nil
+}
+
+func runCacheClear(names []string) error {
+	cache, err := provider.NewCache()
+	if err != nil {
+		return err
+	}
+	removed, err := cache.Clear(names...)
+	for _, name := range removed {
+		fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render("Removed "+name)))
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Println(dimStyle.Render("  Nothing to clear."))
+	}
+	return nil
+}
+
+func runCacheRefresh(names []string) error {
+	providers, err := catalogProviders()
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		wanted := make(map[string]bool)
+		for _, n := range names {
+			wanted[n] = true
+		}
+		var selected []*provider.Cached
+		for _, p := range providers {
+			if wanted[p.Name()] {
+				selected = append(selected, p)
+				delete(wanted, p.Name())
+			}
+		}
+		for n := range wanted {
+			return fmt.Errorf("no configured catalog named %q", n)
+		}
+		providers = selected
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	failed := 0
+	for _, p := range providers {
+		entries, status, err := p.Load(ctx, true)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s: %v", p.Name(), err)))
+		case status.FetchErr != nil:
+			failed++
+			fmt.Println(warnStyle.Render(fmt.Sprintf("  %s: %v; keeping cached copy (fetched %s)", p.Name(), status.FetchErr, provider.FormatAge(status.Age))))
+		default:
+			note := "updated"
+			if status.NotModified {
+				note = "unchanged"
+			}
+			fmt.Println(fmt.Sprintf("  %s %s %s", checkMark, accentStyle.Render(p.Name()), dimStyle.Render(fmt.Sprintf("%d entries, %s", len(entries), note))))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d catalog(s) could not be refreshed", failed)
+	}
+	return nil
+}
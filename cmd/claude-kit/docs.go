@@ -1,16 +1,29 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/huh/spinner"
 	"github.com/spf13/cobra"
 
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
 	"github.com/AdeptMind/infra-tool/claude-cli/internal/docsindex"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
 )
 
-var docsRefresh bool
+var (
+	docsRefresh   bool
+	docsCheck     bool
+	docsMaxTokens int
+	docsMaxAge    int
+)
+
+// errDocsStale makes `ck docs --check` exit non-zero.
+var errDocsStale = errors.New("docs-index is stale")
 
 var docsCmd = &cobra.Command{
 	Use:   "docs",
@@ -19,25 +32,70 @@ var docsCmd = &cobra.Command{
 with framework-specific directives that Claude can use for context.
 
 The docs-index is written to .claude/docs-index.md along with metadata
-in .claude/.docs-meta.json for staleness tracking.
-
-Use --refresh to force regeneration even if the current index is fresh.`,
+in .claude/.docs-meta.json for staleness tracking. Directives can be
+replaced, extended or suppressed per technology with files in
+.claude/docs-overrides/<tech>.md.
+
+Use --refresh to force regeneration even if the current index is fresh.
+
+Use --max-tokens to cap the estimated size of docs-index.md. Directives are
+trimmed by priority (frameworks before languages before tools, overridden
+directives last) and the trimmed ones are reported. The budget is remembered
+in .docs-meta.json; pass --max-tokens 0 to remove it.
+
+Use --check to report staleness without regenerating; it exits non-zero when
+the index is stale, for pre-commit hooks and CI. The index is stale when
+dependency files, the detected stack, docs overrides, the template's stack
+definitions or the ck version changed, or it is older than --max-age days
+(default 14, remembered once set).`,
 	RunE: runDocs,
 }
 
 func init() {
 	docsCmd.Flags().BoolVar(&docsRefresh, "refresh", false, "Force regenerate even if fresh")
+	docsCmd.Flags().IntVar(&docsMaxTokens, "max-tokens", 0, "Token budget for docs-index.md (0 = unlimited; default: previous budget)")
+	docsCmd.Flags().BoolVar(&docsCheck, "check", false, "Only check freshness; exit non-zero if stale")
+	docsCmd.Flags().IntVar(&docsMaxAge, "max-age", 0, "Days before the index is stale (default: previous setting or 14)")
 }
 
 func runDocs(cmd *cobra.Command, args []string) error {
 	projectRoot := resolveProjectRoot()
 
+	opts := docsOptions(projectRoot)
+	budgetChanged := cmd.Flags().Changed("max-tokens")
+	if budgetChanged {
+		opts.MaxTokens = docsMaxTokens
+	}
+	ageChanged := cmd.Flags().Changed("max-age")
+	if ageChanged {
+		opts.MaxAgeDays = docsMaxAge
+	}
+
+	if docsCheck {
+		stale, reason := docsindex.IsStale(projectRoot, opts)
+		if stale {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  Docs-index is stale: %s", reason)))
+			fmt.Fprintln(os.Stderr, dimStyle.Render("    Run ck docs to regenerate."))
+			return errDocsStale
+		}
+		fmt.Println(fmt.Sprintf("  %s %s", checkMark, dimStyle.Render("Docs-index is up to date.")))
+		return nil
+	}
+
 	fmt.Println(banner())
 
-	// Check staleness unless --refresh
-	if !docsRefresh {
-		stale, reason := docsindex.IsStale(projectRoot)
+	// Check staleness unless --refresh or a new budget was given
+	if !docsRefresh && !budgetChanged {
+		stale, reason := docsindex.IsStale(projectRoot, opts)
 		if !stale {
+			if ageChanged {
+				if err := docsindex.SetMaxAge(projectRoot, opts.MaxAgeDays); err != nil {
+					return fmt.Errorf("saving --max-age: %w", err)
+				}
+				fmt.Println(fmt.Sprintf("  %s %s", checkMark, dimStyle.Render(fmt.Sprintf("Max age set to %d days.", opts.MaxAgeDays))))
+			}
 			fmt.Println(fmt.Sprintf("  %s %s", checkMark, dimStyle.Render("Docs-index is up to date.")))
 			fmt.Println(dimStyle.Render("    Use --refresh to force regeneration."))
 			fmt.Println()
@@ -46,11 +104,11 @@ func runDocs(cmd *cobra.Command, args []string) error {
 		fmt.Println(warnStyle.Render(fmt.Sprintf("  %s Regenerating: %s", bullet, reason)))
 	}
 
-	var techs []string
+	var result *docsindex.Result
 	var genErr error
 
 	action := func() {
-		techs, genErr = docsindex.Generate(projectRoot)
+		result, genErr = docsindex.Generate(projectRoot, opts)
 	}
 
 	if err := spinner.New().
@@ -65,15 +123,59 @@ func runDocs(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render("Generated .claude/docs-index.md")))
+	refreshClaudeMD(resolveTemplateDir(), filepath.Join(projectRoot, ".claude"))
 
-	if len(techs) > 0 {
-		fmt.Println(infoStyle.Render(fmt.Sprintf("    %s Detected stack: %s", arrow, strings.Join(techs, ", "))))
+	if len(result.Stack) > 0 {
+		fmt.Println(infoStyle.Render(fmt.Sprintf("    %s Detected stack: %s", arrow, strings.Join(result.Stack, ", "))))
 	} else {
 		fmt.Println(dimStyle.Render("    No stack detected. Add dependency files and re-run."))
 	}
+	printDocsSize(result)
+	printRecommendations(projectRoot)
 
 	fmt.Println(dimStyle.Render("    Metadata: .claude/.docs-meta.json"))
 	fmt.Println()
 
 	return nil
 }
+
+// printRecommendations suggests kit components for detected databases and
+// cloud providers that are not installed yet.
+func printRecommendations(projectRoot string) {
+	targetDir := filepath.Join(projectRoot, ".claude")
+	for _, rec := range stack.Recommend(detectedStackNames(projectRoot)) {
+		k, ok := catalog.LookupKind(rec.Kind)
+		if !ok || catalog.IsInstalled(targetDir, rec.Kind, rec.Name) {
+			continue
+		}
+		fmt.Println(infoStyle.Render(fmt.Sprintf("    %s Recommended: ck add %s %s", arrow, k.Singular(), rec.Name)) +
+			dimStyle.Render(fmt.Sprintf(" (%s)", rec.Reason())))
+	}
+}
+
+// docsOptions returns generation options for this ck version, keeping the
+// token budget and max age recorded by the last generation.
+func docsOptions(projectRoot string) docsindex.Options {
+	opts := docsindex.Options{Version: version, TemplateDir: resolveTemplateDir()}
+	if meta, err := docsindex.LoadMeta(projectRoot); err == nil {
+		opts.MaxTokens = meta.MaxTokens
+		opts.MaxAgeDays = meta.MaxAgeDays
+	}
+	return opts
+}
+
+// printDocsSize reports the estimated size and any trimmed directives.
+func printDocsSize(result *docsindex.Result) {
+	size := fmt.Sprintf("~%d tokens", result.EstimatedTokens)
+	if result.MaxTokens > 0 {
+		size += fmt.Sprintf(" (budget %d)", result.MaxTokens)
+	}
+	fmt.Println(dimStyle.Render(fmt.Sprintf("    Size: %s", size)))
+
+	if len(result.Trimmed) > 0 {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("    %s Trimmed to fit: %s", bullet, strings.Join(result.Trimmed, ", "))))
+	}
+	if result.MaxTokens > 0 && result.EstimatedTokens > result.MaxTokens {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("    %s Still over budget: the stack summary alone exceeds %d tokens", bullet, result.MaxTokens)))
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
 )
 
 var initCmd = &cobra.Command{
@@ -31,6 +32,7 @@ remove installed agents and their dependencies.`,
 func runInteractiveInit() error {
 	tmplDir := resolveTemplateDir()
 	targetDir := resolveTarget()
+	setupTransforms(true)
 
 	fmt.Println(banner())
 
@@ -82,8 +84,20 @@ func runInteractiveInit() error {
 	}
 	fmt.Println(dimStyle.Render(fmt.Sprintf("  Template: %s", tmplDir)))
 	fmt.Println(dimStyle.Render(fmt.Sprintf("  Target:   %s", targetDir)))
+
+	// Detected stack drives pre-selection of conditional components
+	stackNames := detectedStackNames(resolveProjectRoot())
+	if len(stackNames) > 0 {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  Stack:    %s", strings.Join(stackNames, ", "))))
+	}
 	fmt.Println()
 
+	// Components recommended for detected databases and cloud providers
+	recommended := make(map[string]bool)
+	for _, rec := range stack.Recommend(stackNames) {
+		recommended[rec.Kind+"/"+rec.Name] = true
+	}
+
 	// Step 1: Ask if user wants BMAD methodology (skip if already installed)
 	useBmad := false
 	bmadAlreadyInstalled := installedAgents["product-owner"] && installedAgents["architect"] && installedAgents["tech-lead"]
@@ -105,8 +119,11 @@ func runInteractiveInit() error {
 	// BMAD agents that aren't installed yet get pre-selected
 	bmadAgents := map[string]bool{"product-owner": true, "architect": true, "tech-lead": true}
 
+	// Agents whose `when:` conditions match the stack are pre-selected;
+	// agents for other stacks are listed last and marked.
 	var preselected []string
 	options := make([]huh.Option[string], 0, len(agentComps))
+	var irrelevant []huh.Option[string]
 	for _, c := range agentComps {
 		if installedAgents[c.Name] {
 			continue // skip already installed
@@ -119,11 +136,17 @@ func runInteractiveInit() error {
 			}
 			label = fmt.Sprintf("%s -- %s", c.Name, desc)
 		}
+		if !c.When.Matches(stackNames) {
+			label = fmt.Sprintf("%s (not detected: %s)", label, strings.Join(c.When.Stack, ", "))
+			irrelevant = append(irrelevant, huh.NewOption(label, c.Name))
+			continue
+		}
 		options = append(options, huh.NewOption(label, c.Name))
-		if useBmad && bmadAgents[c.Name] {
+		if (useBmad && bmadAgents[c.Name]) || !c.When.IsEmpty() || recommended["agents/"+c.Name] {
 			preselected = append(preselected, c.Name)
 		}
 	}
+	options = append(options, irrelevant...)
 
 	if len(options) == 0 {
 		fmt.Println(successStyle.Render(fmt.Sprintf("  %s All agents already installed!", arrow)))
@@ -224,6 +247,35 @@ func runInteractiveInit() error {
 	// Always add ck-sync command
 	commandSet["ck-sync"] = true
 
+	// Add conditional skills and rules that target the detected stack, and
+	// drop auto-selected ones whose conditions don't hold for this project.
+	var skipped []string
+	for _, cat := range categories {
+		var set map[string]bool
+		switch cat.Name {
+		case "skills":
+			set = skillSet
+		case "rules":
+			set = ruleSet
+		default:
+			continue
+		}
+		for _, c := range cat.Components {
+			if recommended[cat.Name+"/"+c.Name] {
+				set[c.Name] = true
+			}
+			if c.When.IsEmpty() {
+				continue
+			}
+			if c.When.Matches(stackNames) {
+				set[c.Name] = true
+			} else if set[c.Name] {
+				delete(set, c.Name)
+				skipped = append(skipped, cat.Name+"/"+c.Name)
+			}
+		}
+	}
+
 	// Convert sets to sorted slices for display
 	skills := sortedKeys(skillSet)
 	commands := sortedKeys(commandSet)
@@ -259,6 +311,13 @@ func runInteractiveInit() error {
 			dimStyle.Render(teammateMode),
 		))
 	}
+	if len(skipped) > 0 {
+		fmt.Println(fmt.Sprintf("    %s %s: %s",
+			dot,
+			warnStyle.Render("skipped (stack mismatch)"),
+			dimStyle.Render(strings.Join(skipped, ", ")),
+		))
+	}
 
 	fmt.Println()
 
@@ -334,6 +393,9 @@ func runInteractiveInit() error {
 		}
 	}
 
+	// Fill CLAUDE.md managed sections now that components are in place
+	refreshClaudeMD(tmplDir, targetDir)
+
 	fmt.Println()
 	fmt.Println(successStyle.Render(fmt.Sprintf("  %s Setup complete!", arrow)))
 	fmt.Println(dimStyle.Render("  Run 'ck add' for more agents, 'ck remove' to remove components."))
@@ -341,6 +403,15 @@ func runInteractiveInit() error {
 	return nil
 }
 
+// detectedStackNames returns the names of the technologies detected in the project.
+func detectedStackNames(projectRoot string) []string {
+	var names []string
+	for _, t := range stack.DetectStack(projectRoot) {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
 // sortedKeys returns the keys of a map sorted alphabetically.
 func sortedKeys(m map[string]bool) []string {
 	keys := make([]string, 0, len(m))
@@ -14,6 +14,7 @@ import (
 var (
 	listAvailable bool
 	listInstalled bool
+	listVerbose   bool
 )
 
 var listCmd = &cobra.Command{
@@ -22,13 +23,15 @@ var listCmd = &cobra.Command{
 	Long: `Show a table of BMAD template components.
 
 By default, shows both available and installed components side by side.
-Use --available or --installed to filter.`,
+Use --available or --installed to filter, and -v to show the effective
+globs of installed rules (after per-project rescoping).`,
 	RunE: runList,
 }
 
 func init() {
 	listCmd.Flags().BoolVar(&listAvailable, "available", false, "Show available components only")
 	listCmd.Flags().BoolVar(&listInstalled, "installed", false, "Show installed components only")
+	listCmd.Flags().BoolVarP(&listVerbose, "verbose", "v", false, "Show effective globs of installed rules")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
@@ -44,12 +47,21 @@ func runList(cmd *cobra.Command, args []string) error {
 	targetDir := resolveTarget()
 	installed, _ := catalog.GetInstalled(targetDir)
 
-	// Build a set of installed component keys
+	// Build a set of installed component keys, flagging installed components
+	// whose `when:` conditions no longer match the project's stack
+	stackNames := detectedStackNames(resolveProjectRoot())
 	installedSet := make(map[string]bool)
+	installedFiles := make(map[string]string)
+	mismatched := make(map[string]catalog.Conditions)
 	installedCount := 0
 	for _, cat := range installed {
 		for _, c := range cat.Components {
-			installedSet[cat.Name+"/"+c.Name] = true
+			key := cat.Name + "/" + c.Name
+			installedSet[key] = true
+			installedFiles[key] = c.File
+			if !c.When.Matches(stackNames) {
+				mismatched[key] = c.When
+			}
 			installedCount++
 		}
 	}
@@ -66,6 +78,12 @@ func runList(cmd *cobra.Command, args []string) error {
 		lipgloss.NewStyle().Foreground(dim).Render("●"),
 		totalCount-installedCount,
 	)
+	if len(mismatched) > 0 {
+		summary += fmt.Sprintf("  %s %d not matching stack",
+			lipgloss.NewStyle().Foreground(gold).Bold(true).Render("●"),
+			len(mismatched),
+		)
+	}
 	fmt.Println(summary)
 
 	for _, cat := range available {
@@ -84,6 +102,7 @@ func runList(cmd *cobra.Command, args []string) error {
 
 		fmt.Println(sectionHeader(strings.ToUpper(cat.Name)))
 
+		showGlobs := listVerbose && cat.Name == "rules"
 		rows := [][]string{}
 		for _, c := range cat.Components {
 			isInst := installedSet[cat.Name+"/"+c.Name]
@@ -106,13 +125,24 @@ func runList(cmd *cobra.Command, args []string) error {
 			if len(desc) > 55 {
 				desc = desc[:52] + "..."
 			}
-			if isInst {
+			if when, ok := mismatched[cat.Name+"/"+c.Name]; ok {
+				status = warnStyle.Render("!")
+				desc = warnStyle.Render(fmt.Sprintf("%s (stack mismatch, %s)", desc, when))
+			} else if isInst {
 				desc = lipgloss.NewStyle().Foreground(pink).Render(desc)
 			} else {
 				desc = dimStyle.Render(desc)
 			}
 
-			rows = append(rows, []string{status, nameRendered, desc})
+			row := []string{status, nameRendered, desc}
+			if showGlobs {
+				globs := ""
+				if isInst {
+					globs = strings.Join(catalog.ExtractGlobs(installedFiles[cat.Name+"/"+c.Name]), ", ")
+				}
+				row = append(row, infoStyle.Render(globs))
+			}
+			rows = append(rows, row)
 		}
 
 		if len(rows) == 0 {
@@ -120,13 +150,18 @@ func runList(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		headers := []string{
+			"",
+			tableHeaderStyle.Render("Name"),
+			tableHeaderStyle.Render("Description"),
+		}
+		if showGlobs {
+			headers = append(headers, tableHeaderStyle.Render("Globs"))
+		}
+
 		t := table.New().
 			Border(lipgloss.HiddenBorder()).
-			Headers(
-				"",
-				tableHeaderStyle.Render("Name"),
-				tableHeaderStyle.Render("Description"),
-			).
+			Headers(headers...).
 			Rows(rows...).
 			StyleFunc(func(row, col int) lipgloss.Style {
 				s := lipgloss.NewStyle().PaddingRight(2)
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/AdeptMind/infra-tool/claude-cli/internal/config"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
 )
 
 var version = "dev"
@@ -37,6 +39,8 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	cobra.OnInitialize(loadStackDefinitions)
+
 	rootCmd.PersistentFlags().StringVar(&templateDir, "template-dir", "", "Override template directory path")
 	rootCmd.PersistentFlags().StringVarP(&projectDir, "project", "f", "", "Project directory (default: current directory)")
 
@@ -49,6 +53,11 @@ func init() {
 	rootCmd.AddCommand(docsCmd)
 	rootCmd.AddCommand(teammateModeCmd)
 	rootCmd.AddCommand(depCmd)
+	rootCmd.AddCommand(varsCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(stackCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(cacheCmd)
 }
 
 func resolveTemplateDir() string {
@@ -79,6 +88,19 @@ func resolveTarget() string {
 	return filepath.Join(resolveProjectRoot(), ".claude")
 }
 
+// loadStackDefinitions registers the template's stacks/*.yaml definitions
+// so detection and the docs index pick them up. Invalid files are reported
+// and skipped.
+func loadStackDefinitions() {
+	defs, err := stack.LoadDefinitions(filepath.Join(resolveTemplateDir(), stack.StacksDirName))
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintln(os.Stderr, warnStyle.Render("  Stack definitions: "+line))
+		}
+	}
+	stack.RegisterDefinitions(defs...)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
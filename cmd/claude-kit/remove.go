@@ -25,6 +25,10 @@ Examples:
 	RunE: runRemove,
 }
 
+func init() {
+	removeCmd.Long += "\n\n" + componentTypesHelp()
+}
+
 func runRemove(cmd *cobra.Command, args []string) error {
 	targetDir := resolveTarget()
 
@@ -125,6 +129,7 @@ func runInteractiveRemove(targetDir string) error {
 		}
 		fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Removed %s", key))))
 	}
+	refreshClaudeMD(resolveTemplateDir(), targetDir)
 
 	fmt.Println()
 	return nil
@@ -151,6 +156,7 @@ func removeComponents(targetDir, compType string, names []string) error {
 		}
 		fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Removed %s/%s", compType, name))))
 	}
+	refreshClaudeMD(resolveTemplateDir(), targetDir)
 	fmt.Println()
 	return nil
 }
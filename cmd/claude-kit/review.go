@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+)
+
+// maxReviewLines is how many lines of each file the review prints.
+const maxReviewLines = 80
+
+// reviewQuarantine shows a staged external component — its files, their
+// contents and any risky patterns — and asks whether to install it. With
+// trust set, contents are not printed and no confirmation is asked.
+func reviewQuarantine(q *external.Quarantine, trust bool) (bool, error) {
+	rel := q.Kind.Name() + "/" + q.Name
+	fmt.Println(sectionHeader("REVIEW " + rel))
+	p := q.Provenance
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  From %s", p.URL)))
+	if p.Commit != "" {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  Commit %s", p.Commit)))
+	}
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  Staged in %s", q.Dir)))
+
+	files, err := q.Files()
+	if err != nil {
+		return false, err
+	}
+	findings, err := q.Scan()
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Println(sectionHeader("FILES"))
+	for _, f := range files {
+		var tags []string
+		if f.Script {
+			tags = append(tags, "script")
+		}
+		if f.Executable {
+			tags = append(tags, "executable")
+		}
+		if f.Binary {
+			tags = append(tags, "binary")
+		}
+		if f.Symlink {
+			tags = append(tags, "symlink")
+		}
+		path := printable(f.Path)
+		line := fmt.Sprintf("  %s %s  %s", bullet, path, dimStyle.Render(formatSize(f.Size)))
+		if f.Suspicious() {
+			line = fmt.Sprintf("  %s %s  %s  %s", warnStyle.Render("!"), warnStyle.Render(path), dimStyle.Render(formatSize(f.Size)), warnStyle.Render(strings.Join(tags, ", ")))
+		}
+		fmt.Println(line)
+	}
+
+	if !trust {
+		fmt.Println(sectionHeader("CONTENTS"))
+		for _, f := range files {
+			if f.Binary || f.Symlink {
+				continue
+			}
+			printStagedFile(q, f)
+		}
+	}
+
+	printRisks(findings)
+	if trust {
+		fmt.Println(dimStyle.Render("  Installing without confirmation (--trust)."))
+		return true, nil
+	}
+	return confirmInstall(fmt.Sprintf("Install %s into .claude/?", rel), findings)
+}
+
+// printRisks lists risky patterns found in a staged component.
+func printRisks(findings []external.Finding) {
+	fmt.Println(sectionHeader("RISKS"))
+	if len(findings) == 0 {
+		fmt.Println(fmt.Sprintf("  %s %s", checkMark, dimStyle.Render("No risky patterns found")))
+	}
+	for _, f := range findings {
+		mark := warnStyle.Render("! warning")
+		if f.Severity == external.High {
+			mark = errorStyle.Render("✗ high   ")
+		}
+		fmt.Println(fmt.Sprintf("  %s  %s", mark, printable(f.String())))
+		if f.Text != "" {
+			fmt.Println(dimStyle.Render("             " + printable(f.Text)))
+		}
+	}
+	fmt.Println()
+}
+
+// confirmInstall asks the user to confirm, defaulting to no when a
+// high-risk pattern was found.
+func confirmInstall(title string, findings []external.Finding) (bool, error) {
+	install := !external.HasHigh(findings)
+	desc := "No risky patterns found."
+	if len(findings) > 0 {
+		desc = fmt.Sprintf("%d finding(s) above; review them before installing.", len(findings))
+	}
+	err := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(title).
+				Description(desc).
+				Value(&install),
+		),
+	).WithTheme(ckTheme()).Run()
+	if err != nil {
+		return false, err
+	}
+	return install, nil
+}
+
+// printStagedFile prints a staged text file with line numbers, up to
+// maxReviewLines.
+func printStagedFile(q *external.Quarantine, f external.File) {
+	data, err := q.ReadFile(f.Path)
+	if err != nil {
+		return
+	}
+	fmt.Println(accentStyle.Render(fmt.Sprintf("  ── %s ──", printable(f.Path))))
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	for i, line := range lines {
+		if i == maxReviewLines {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  … %d more lines", len(lines)-maxReviewLines)))
+			break
+		}
+		fmt.Println(fmt.Sprintf("  %s %s", dimStyle.Render(fmt.Sprintf("%4d │", i+1)), printable(line)))
+	}
+	fmt.Println()
+}
+
+// printable replaces control characters other than tab, and bytes that
+// are not valid UTF-8, with visible escapes such as \x1b so that staged
+// content cannot move the cursor, recolour or hide lines of the review.
+func printable(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&sb, "\\x%02x", s[i])
+		case r != '\t' && (r < 0x20 || r == 0x7f || (r >= 0x80 && r <= 0x9f)):
+			fmt.Fprintf(&sb, "\\x%02x", r)
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func formatSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
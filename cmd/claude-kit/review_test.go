@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestPrintable(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text, naïve", "plain text, naïve"},
+		{"a\tb", "a\tb"},
+		{"\x1b[2Jclear", `\x1b[2Jclear`},
+		{"csi \u009b2J", `csi \x9b2J`},
+		{"bell\a del\x7f", `bell\x07 del\x7f`},
+		{"bad \xff\xfe utf-8", `bad \xff\xfe utf-8`},
+		{"line\r\n", `line\x0d\x0a`},
+	}
+	for _, tt := range tests {
+		if got := printable(tt.in); got != tt.want {
+			t.Errorf("printable(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/config"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/search"
+)
+
+var (
+	searchType   string
+	searchSource string
+	searchLimit  int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <terms...>",
+	Short: "Full-text search of template components",
+	Long: `Search component names, descriptions, frontmatter tags and content
+across all template sources and print ranked matches with snippets.
+Runs locally; Claude is not involved.
+
+Sources are the template directory ("local"), every directory under
+~/.bmad/sources/, and name=dir pairs in $BMAD_TEMPLATE_SOURCES.
+
+Examples:
+  ck search database migration
+  ck search --type skills auth
+  ck search --source team deploy`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().StringVarP(&searchType, "type", "t", "", "Only search one component type (e.g. skills, agent)")
+	searchCmd.Flags().StringVarP(&searchSource, "source", "s", "", "Only search one template source (e.g. local, team)")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 10, "Maximum number of results")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+
+	kind := ""
+	if searchType != "" {
+		k, ok := catalog.LookupKind(searchType)
+		if !ok {
+			return fmt.Errorf("unknown component type %q (valid: %s)", searchType, strings.Join(catalog.KindNames(), ", "))
+		}
+		kind = k.Name()
+	}
+
+	sources := append([]config.Source{{Name: config.LocalSourceName, Dir: resolveTemplateDir()}}, config.ExtraTemplateSources()...)
+	if searchSource != "" {
+		var names []string
+		var selected []config.Source
+		for _, s := range sources {
+			names = append(names, s.Name)
+			if s.Name == searchSource {
+				selected = append(selected, s)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("unknown source %q (configured: %s)", searchSource, strings.Join(names, ", "))
+		}
+		sources = selected
+	}
+
+	var docs []search.Document
+	for _, s := range sources {
+		categories, err := catalog.ScanTemplate(s.Dir)
+		if err != nil {
+			if searchSource != "" {
+				return fmt.Errorf("scanning source %s: %w", s.Name, err)
+			}
+			continue
+		}
+		for _, d := range search.CatalogDocuments(s.Name, categories) {
+			if kind == "" || d.Kind == kind {
+				docs = append(docs, d)
+			}
+		}
+	}
+
+	fmt.Println(banner())
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  Query: %s", query)))
+
+	results := search.NewIndex(docs).Search(query, searchLimit)
+	if len(results) == 0 {
+		fmt.Println()
+		fmt.Println(warnStyle.Render(fmt.Sprintf("  No components match %q (%d searched).", query, len(docs))))
+		fmt.Println(dimStyle.Render("  Try fewer or broader terms, or 'ck add new' to search external catalogs."))
+		fmt.Println()
+		return nil
+	}
+
+	fmt.Println(sectionHeader(fmt.Sprintf("%d MATCHES", len(results))))
+
+	markStyle := lipgloss.NewStyle().Foreground(gold).Bold(true)
+	mark := func(s string) string { return markStyle.Render(s) }
+	for i, r := range results {
+		fmt.Println(fmt.Sprintf("  %s %s %s",
+			accentStyle.Render(fmt.Sprintf("%2d.", i+1)),
+			search.Highlight(r.Kind+"/"+r.Name, query, mark),
+			dimStyle.Render(fmt.Sprintf("[%s] %.2f", r.Source, r.Score)),
+		))
+		if r.Description != "" {
+			fmt.Println("      " + search.Highlight(r.Description, query, mark))
+		}
+		if snippet := search.Snippet(r.Body, query, 100); snippet != "" {
+			fmt.Println("      " + dimStyle.Render("“") + search.Highlight(snippet, query, mark) + dimStyle.Render("”"))
+		}
+		fmt.Println(dimStyle.Render("      " + installHint(r.Document)))
+	}
+	fmt.Println()
+	return nil
+}
+
+// installHint returns the command that installs a search result.
+func installHint(d search.Document) string {
+	singular := d.Kind
+	if k, ok := catalog.LookupKind(d.Kind); ok {
+		singular = k.Singular()
+	}
+	if d.Source == config.LocalSourceName {
+		return fmt.Sprintf("ck add %s %s", singular, d.Name)
+	}
+	for _, s := range config.ExtraTemplateSources() {
+		if s.Name == d.Source {
+			return fmt.Sprintf("ck --template-dir %s add %s %s", s.Dir, singular, d.Name)
+		}
+	}
+	return fmt.Sprintf("ck add %s %s", singular, d.Name)
+}
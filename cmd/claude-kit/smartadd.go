@@ -1,76 +1,97 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/huh/spinner"
 
 	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/config"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/provider"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/recommend"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/search"
 )
 
-// Recommendation represents a component suggested by Claude.
-type Recommendation struct {
-	Source      string `json:"source"`                // "local", "voltagent", "aitmpl"
-	Type       string `json:"type"`                  // "agents", "skills", "commands", "rules"
-	Name       string `json:"name"`                  // component name
-	Description string `json:"description"`           // what it does
-	URL        string `json:"url,omitempty"`          // source URL for external components
-}
+// Recommendation represents a component suggested by smart add, either by
+// a model or by the offline search index.
+type Recommendation = recommend.Recommendation
 
 func runSmartAdd(tmplDir, targetDir, query string) error {
 	fmt.Println(banner())
+
+	// --offline needs neither a backend nor catalog providers
+	if addOffline {
+		return offlineSmartAdd(tmplDir, targetDir, query)
+	}
+
+	// Use a model only when the configured backend is usable
+	recommender, err := recommend.New(addRecommender)
+	if err != nil {
+		return err
+	}
+	if err := recommender.Available(); err != nil {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("  %v; ranking local components offline", err)))
+		return offlineSmartAdd(tmplDir, targetDir, query)
+	}
+	providers, err := catalogProviders()
+	if err != nil {
+		return err
+	}
+
 	fmt.Println(subtitleStyle.Render("  Smart Add — AI-powered component discovery"))
 	fmt.Println(dimStyle.Render(fmt.Sprintf("  Query: %s", query)))
 	fmt.Println()
 
-	// Check claude CLI is available
-	if _, err := exec.LookPath("claude"); err != nil {
-		return fmt.Errorf("claude CLI not found in PATH; smart add requires the Claude Code CLI")
-	}
-
 	// Build local catalog
 	localCatalog := buildLocalCatalog(tmplDir)
 
 	// Fetch external catalogs with spinner
-	var voltAgentCatalog string
-	var fetchErr error
+	var external []catalogResult
 	_ = spinner.New().
 		Title("Fetching external catalogs...").
 		Action(func() {
-			voltAgentCatalog, fetchErr = fetchVoltAgent()
+			external = fetchCatalogs(providers)
 		}).
 		Run()
 
-	if fetchErr != nil {
-		fmt.Println(warnStyle.Render(fmt.Sprintf("  Could not fetch VoltAgent catalog: %v", fetchErr)))
-		voltAgentCatalog = ""
+	for _, res := range external {
+		switch {
+		case res.err != nil:
+			fmt.Println(warnStyle.Render(fmt.Sprintf("  Could not fetch %s catalog: %v", res.name, res.err)))
+		case res.status.FetchErr != nil:
+			fmt.Println(warnStyle.Render(fmt.Sprintf("  Could not fetch %s catalog; using cached copy (fetched %s)", res.name, provider.FormatAge(res.status.Age))))
+		case res.status.FromCache && !res.status.NotModified:
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  Using cached %s catalog (fetched %s)", res.name, provider.FormatAge(res.status.Age))))
+		}
 	}
 
-	// Build prompt and run Claude
-	prompt := buildSmartAddPrompt(query, localCatalog, voltAgentCatalog)
+	// Build prompt and ask the model
+	prompt := buildSmartAddPrompt(query, localCatalog, external)
 
 	var recommendations []Recommendation
-	var claudeErr error
+	var recErr error
 	_ = spinner.New().
-		Title("Asking Claude for recommendations...").
+		Title(fmt.Sprintf("Asking %s for recommendations...", recommender.Name())).
 		Action(func() {
-			recommendations, claudeErr = runClaudeRecommend(prompt)
+			recommendations, recErr = recommend.Recommend(context.Background(), recommender, prompt)
 		}).
 		Run()
 
-	if claudeErr != nil {
-		return fmt.Errorf("recommendation failed: %w", claudeErr)
+	if recErr != nil {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("  Recommendation failed: %v", recErr)))
+		fmt.Println(dimStyle.Render("  Falling back to offline search of local components."))
+		return runOfflineSmartAdd(tmplDir, targetDir, query)
 	}
 
+	recommendations = reconcileRecommendations(tmplDir, recommendations)
 	if len(recommendations) == 0 {
 		fmt.Println(warnStyle.Render("  No matching components found for your query."))
 		return nil
@@ -80,6 +101,106 @@ func runSmartAdd(tmplDir, targetDir, query string) error {
 	return presentRecommendations(tmplDir, targetDir, recommendations)
 }
 
+// reconcileRecommendations matches local recommendations to real template
+// components and drops external ones whose URL is malformed or unreachable,
+// printing a note for each change.
+func reconcileRecommendations(tmplDir string, recs []Recommendation) []Recommendation {
+	var notes []recommend.Note
+	if categories, err := catalog.ScanTemplate(tmplDir); err == nil {
+		var localNotes []recommend.Note
+		recs, localNotes = recommend.ReconcileLocal(recs, categories)
+		notes = append(notes, localNotes...)
+	}
+
+	var externalNotes []recommend.Note
+	_ = spinner.New().
+		Title("Checking external components...").
+		Action(func() {
+			recs, externalNotes = recommend.CheckExternal(context.Background(), nil, recs)
+		}).
+		Run()
+	notes = append(notes, externalNotes...)
+
+	for _, n := range notes {
+		if n.Dropped {
+			fmt.Println(warnStyle.Render(fmt.Sprintf("  Dropped %s", n)))
+		} else {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  Corrected %s", n)))
+		}
+	}
+	return recs
+}
+
+// offlineSmartAdd prints the offline header and runs runOfflineSmartAdd.
+func offlineSmartAdd(tmplDir, targetDir, query string) error {
+	fmt.Println(subtitleStyle.Render("  Smart Add — offline search of local and cached components"))
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  Query: %s", query)))
+	fmt.Println()
+	for _, r := range cachedCatalogs() {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  Using cached %s catalog (fetched %s)", r.Name, provider.FormatAge(r.Age()))))
+	}
+	return runOfflineSmartAdd(tmplDir, targetDir, query)
+}
+
+// runOfflineSmartAdd ranks local template components and cached catalog
+// entries against the query with the built-in search index and lets the
+// user pick from the results.
+func runOfflineSmartAdd(tmplDir, targetDir, query string) error {
+	recommendations, err := recommendOffline(tmplDir, query, cachedCatalogs())
+	if err != nil {
+		return err
+	}
+	if len(recommendations) == 0 {
+		fmt.Println(warnStyle.Render("  No matching components found for your query."))
+		return nil
+	}
+	return presentRecommendations(tmplDir, targetDir, recommendations)
+}
+
+// recommendOffline returns the local components and cached catalog entries
+// best matching the query.
+func recommendOffline(tmplDir, query string, cached []*provider.Record) ([]Recommendation, error) {
+	const maxResults = 10
+
+	categories, err := catalog.ScanTemplate(tmplDir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning templates: %w", err)
+	}
+	docs := search.CatalogDocuments(config.LocalSourceName, categories)
+	for _, r := range cached {
+		for _, e := range r.Entries {
+			if e.URL == "" {
+				continue
+			}
+			docs = append(docs, search.Document{Source: r.Name, Kind: e.Type, Name: e.Name, Description: e.Description, URL: e.URL})
+		}
+	}
+	index := search.NewIndex(docs)
+
+	var recs []Recommendation
+	for _, r := range index.Search(query, maxResults) {
+		recs = append(recs, Recommendation{
+			Source:      r.Source,
+			Type:        r.Kind,
+			Name:        r.Name,
+			Description: r.Description,
+			URL:         r.URL,
+		})
+	}
+	return recs, nil
+}
+
+// cachedCatalogs returns the catalogs in the catalog cache, whatever their
+// age, without touching the network.
+func cachedCatalogs() []*provider.Record {
+	cache := &provider.Cache{Dir: config.CatalogCacheDir()}
+	records, err := cache.List()
+	if err != nil {
+		return nil
+	}
+	return records
+}
+
 // buildLocalCatalog produces a text summary of all local template components.
 func buildLocalCatalog(tmplDir string) string {
 	categories, err := catalog.ScanTemplate(tmplDir)
@@ -101,35 +222,88 @@ func buildLocalCatalog(tmplDir string) string {
 	return sb.String()
 }
 
-// fetchVoltAgent downloads the VoltAgent awesome-agent-skills README.
-func fetchVoltAgent() (string, error) {
-	url := "https://raw.githubusercontent.com/VoltAgent/awesome-agent-skills/main/README.md"
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(url)
+// catalogProviders returns the external catalog providers, backed by the
+// catalog cache: those in the $BMAD_CATALOG_PROVIDERS file (VoltAgent and
+// aitmpl.com by default) plus any given with --catalog.
+func catalogProviders() ([]*provider.Cached, error) {
+	configs, err := provider.Configs()
 	if err != nil {
-		return "", fmt.Errorf("GET %s: %w", url, err)
+		return nil, err
+	}
+	cache, err := provider.NewCache()
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range addCatalogs {
+		c, err := provider.ParseFlag(value)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, c)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	providers := make([]*provider.Cached, 0, len(configs))
+	for _, c := range configs {
+		p, err := provider.NewCached(c, cache)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
 	}
+	return providers, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+// catalogResult holds one provider's entries or the error fetching them.
+type catalogResult struct {
+	name    string
+	entries []provider.Entry
+	status  provider.Status
+	err     error
+}
+
+// fetchCatalogs loads all providers in parallel, keeping their order.
+func fetchCatalogs(providers []*provider.Cached) []catalogResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	results := make([]catalogResult, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries, status, err := p.Load(ctx, false)
+			results[i] = catalogResult{name: p.Name(), entries: entries, status: status, err: err}
+		}()
 	}
+	wg.Wait()
+	return results
+}
+
+// maxExternalCatalogBytes bounds the external catalog text in the prompt;
+// it is shared evenly between the catalogs.
+const maxExternalCatalogBytes = 20000
 
-	content := string(body)
-	// Truncate if too large to fit in prompt
-	if len(content) > 20000 {
-		content = content[:20000] + "\n... (truncated)"
+// formatCatalog lists entries as prompt lines, stopping at budget bytes.
+func formatCatalog(entries []provider.Entry, budget int) string {
+	var sb strings.Builder
+	for i, e := range entries {
+		desc := e.Description
+		if desc == "" {
+			desc = "(no description)"
+		}
+		line := fmt.Sprintf("- %s/%s: %s — %s\n", e.Type, e.Name, desc, e.URL)
+		if sb.Len()+len(line) > budget {
+			sb.WriteString(fmt.Sprintf("... (%d more entries omitted)\n", len(entries)-i))
+			break
+		}
+		sb.WriteString(line)
 	}
-	return content, nil
+	return sb.String()
 }
 
 // buildSmartAddPrompt creates the prompt sent to Claude for component recommendation.
-func buildSmartAddPrompt(query, localCatalog, voltAgent string) string {
+func buildSmartAddPrompt(query, localCatalog string, external []catalogResult) string {
 	var sb strings.Builder
 
 	sb.WriteString(`You are a Claude Code component recommender for the "claude-kit" CLI tool.
@@ -145,29 +319,33 @@ Based on the user's description, recommend components to install from the availa
 `)
 	sb.WriteString(localCatalog)
 
-	if voltAgent != "" {
-		sb.WriteString(`
+	var fetched []catalogResult
+	for _, res := range external {
+		if res.err == nil && len(res.entries) > 0 {
+			fetched = append(fetched, res)
+		}
+	}
+	for _, res := range fetched {
+		sb.WriteString(fmt.Sprintf(`
 
-## SOURCE 2: VOLTAGENT (external GitHub skills)
-Each entry below links to a GitHub repository containing Claude Code skills.
-`)
-		sb.WriteString(voltAgent)
+## EXTERNAL CATALOG: %s
+Each entry is type/name: description — URL.
+`, res.name))
+		sb.WriteString(formatCatalog(res.entries, maxExternalCatalogBytes/len(fetched)))
 	}
 
 	sb.WriteString(`
 
-## SOURCE 3: AITMPL.COM
-Browse https://www.aitmpl.com for additional Claude Code components (skills, agents, commands, settings, hooks, MCPs).
-Recommend components from this source based on your knowledge of what is available there.
-
 ## INSTRUCTIONS
 
 1. Analyze the user's request
-2. Find matching components from ALL sources (local first, then external)
+2. Find matching components from ALL sources (local first, then external catalogs)
 3. Prefer local components when a good match exists
-4. For VoltAgent entries, include the GitHub repository URL from the README
-5. For aitmpl.com entries, include "https://www.aitmpl.com" as the URL
-6. type must be one of: agents, skills, commands, rules
+4. Only recommend components listed above — never invent names or URLs
+5. For external entries, set source to the catalog name and url to the entry's URL exactly
+6. type must be one of: `)
+	sb.WriteString(strings.Join(catalog.KindNames(), ", "))
+	sb.WriteString(`
 
 Respond with ONLY a valid JSON array — no markdown fences, no explanation, no text before or after:
 [
@@ -193,55 +371,6 @@ If nothing matches, respond with: []
 	return sb.String()
 }
 
-// runClaudeRecommend invokes the Claude CLI non-interactively and parses the JSON response.
-func runClaudeRecommend(prompt string) ([]Recommendation, error) {
-	cmd := exec.Command("claude", "-p", prompt, "--output-format", "text")
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("claude CLI exited with code %d: %s", exitErr.ExitCode(), string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("claude CLI: %w", err)
-	}
-
-	responseStr := strings.TrimSpace(string(output))
-
-	// Extract JSON array from response (Claude might wrap in markdown)
-	jsonStr := extractJSONArray(responseStr)
-	if jsonStr == "" {
-		return nil, fmt.Errorf("no JSON array found in Claude response:\n%s", responseStr)
-	}
-
-	var recs []Recommendation
-	if err := json.Unmarshal([]byte(jsonStr), &recs); err != nil {
-		return nil, fmt.Errorf("invalid JSON in Claude response: %w\nExtracted: %s", err, jsonStr)
-	}
-
-	return recs, nil
-}
-
-// extractJSONArray finds the first JSON array in a string.
-func extractJSONArray(s string) string {
-	start := strings.Index(s, "[")
-	if start < 0 {
-		return ""
-	}
-	// Find matching closing bracket
-	depth := 0
-	for i := start; i < len(s); i++ {
-		switch s[i] {
-		case '[':
-			depth++
-		case ']':
-			depth--
-			if depth == 0 {
-				return s[start : i+1]
-			}
-		}
-	}
-	return ""
-}
-
 // presentRecommendations shows a multi-select form and installs chosen components.
 func presentRecommendations(tmplDir, targetDir string, recs []Recommendation) error {
 	fmt.Println(sectionHeader("Recommendations"))
@@ -287,6 +416,7 @@ func presentRecommendations(tmplDir, targetDir string, recs []Recommendation) er
 			installExternalRec(targetDir, rec)
 		}
 	}
+	refreshClaudeMD(tmplDir, targetDir)
 
 	fmt.Println()
 	fmt.Println(successStyle.Render(fmt.Sprintf("  %s Done!", arrow)))
@@ -324,77 +454,44 @@ func installLocalRec(tmplDir, targetDir string, rec Recommendation) {
 	fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Added %s/%s", rec.Type, rec.Name))))
 }
 
-// installExternalRec installs a component from an external source.
+// installExternalRec stages an external component in quarantine, shows it
+// for review and installs it once confirmed (or with --trust).
 func installExternalRec(targetDir string, rec Recommendation) {
 	if rec.URL == "" {
 		fmt.Println(warnStyle.Render(fmt.Sprintf("  %s/%s [%s]: no URL provided, skipping", rec.Type, rec.Name, rec.Source)))
 		return
 	}
 
-	fmt.Println(infoStyle.Render(fmt.Sprintf("  %s Fetching %s/%s from %s...", bullet, rec.Type, rec.Name, rec.Source)))
+	fmt.Println(infoStyle.Render(fmt.Sprintf("  %s Fetching %s/%s from %s into quarantine...", bullet, rec.Type, rec.Name, rec.Source)))
 
-	if strings.Contains(rec.URL, "github.com") {
-		if err := installFromGitHub(targetDir, rec); err != nil {
-			fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s/%s: %v", rec.Type, rec.Name, err)))
-			return
-		}
-		fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Added %s/%s [%s]", rec.Type, rec.Name, rec.Source))))
+	q, err := external.Fetch(context.Background(), rec.Source, rec.Type, rec.Name, rec.URL)
+	if errors.Is(err, external.ErrUnsupported) {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("  %s/%s: install manually from %s", rec.Type, rec.Name, rec.URL)))
 		return
 	}
-
-	// Non-GitHub external — show URL for manual install
-	fmt.Println(warnStyle.Render(fmt.Sprintf("  %s/%s: install manually from %s", rec.Type, rec.Name, rec.URL)))
-}
-
-// installFromGitHub clones a GitHub repo as a skill directory, or fetches a raw file.
-func installFromGitHub(targetDir string, rec Recommendation) error {
-	// Skills are directories — clone the repo
-	if rec.Type == "skills" {
-		destDir := filepath.Join(targetDir, "skills", rec.Name)
-		if err := os.MkdirAll(filepath.Dir(destDir), 0o755); err != nil {
-			return err
-		}
-
-		cmd := exec.Command("git", "clone", "--depth=1", "--quiet", rec.URL, destDir)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("git clone failed: %w", err)
-		}
-
-		// Remove .git directory from cloned skill
-		_ = os.RemoveAll(filepath.Join(destDir, ".git"))
-		return nil
-	}
-
-	// Agents, commands, rules are single markdown files — try to fetch raw content
-	rawURL := githubToRaw(rec.URL)
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(rawURL)
 	if err != nil {
-		return fmt.Errorf("fetching %s: %w", rawURL, err)
+		fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s/%s: %v", rec.Type, rec.Name, err)))
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("fetching %s: status %d", rawURL, resp.StatusCode)
+	ok, err := reviewQuarantine(q, addTrust)
+	if err != nil || !ok {
+		q.Discard()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s/%s: review failed: %v (use --trust to install without review)", rec.Type, rec.Name, err)))
+			return
+		}
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  Discarded %s/%s", rec.Type, rec.Name)))
+		return
 	}
 
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if catalog.IsInstalled(targetDir, rec.Type, rec.Name) {
+		fmt.Println(warnStyle.Render(fmt.Sprintf("  %s/%s already installed, replacing", rec.Type, rec.Name)))
 	}
-
-	destFile := filepath.Join(targetDir, rec.Type, rec.Name+".md")
-	if err := os.MkdirAll(filepath.Dir(destFile), 0o755); err != nil {
-		return err
+	if err := q.Install(targetDir); err != nil {
+		q.Discard()
+		fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s/%s: %v", rec.Type, rec.Name, err)))
+		return
 	}
-	return os.WriteFile(destFile, content, 0o644)
+	fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Added %s/%s [%s]", rec.Type, rec.Name, rec.Source))))
 }
-
-// githubToRaw converts a GitHub blob/tree URL to a raw.githubusercontent.com URL.
-func githubToRaw(url string) string {
-	url = strings.Replace(url, "github.com", "raw.githubusercontent.com", 1)
-	url = strings.Replace(url, "/blob/", "/", 1)
-	url = strings.Replace(url, "/tree/", "/", 1)
-	return url
-}
-
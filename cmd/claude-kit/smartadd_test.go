@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/provider"
+)
+
+func TestRecommendOffline(t *testing.T) {
+	tmplDir := t.TempDir()
+	for name, content := range map[string]string{
+		"agents/dba.md":             "---\ndescription: Database administrator for Postgres\n---\nTunes slow queries.\n",
+		"agents/docs-writer.md":     "---\ndescription: Writes documentation\n---\n",
+		"skills/sqlx/SKILL.md":      "---\nname: sqlx\ndescription: Compile-time checked SQL for Rust\n---\n",
+		"commands/deploy/README.md": "not a command",
+	} {
+		p := filepath.Join(tmplDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached := []*provider.Record{{
+		Name: "community",
+		Entries: []provider.Entry{
+			{Type: "agents", Name: "postgres-expert", Description: "Postgres tuning and query plans", URL: "https://example.com/postgres-expert.md"},
+			// Entries without a URL cannot be installed and are not offered
+			{Type: "agents", Name: "postgres-ghost", Description: "Postgres"},
+		},
+	}}
+
+	recs, err := recommendOffline(tmplDir, "tune postgres queries", cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The local agent matches every query term, the cached entry two of them
+	want := []Recommendation{
+		{Source: "local", Type: "agents", Name: "dba", Description: "Database administrator for Postgres"},
+		{Source: "community", Type: "agents", Name: "postgres-expert", Description: "Postgres tuning and query plans", URL: "https://example.com/postgres-expert.md"},
+	}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("recommendOffline =\n  %+v\nwant\n  %+v", recs, want)
+	}
+
+	if recs, err := recommendOffline(tmplDir, "kubernetes", cached); err != nil || len(recs) != 0 {
+		t.Errorf("recommendOffline(kubernetes) = %+v, %v; want nothing", recs, err)
+	}
+	if _, err := recommendOffline(filepath.Join(tmplDir, "missing"), "postgres", nil); err == nil {
+		t.Error("want an error for a missing template directory")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
+)
+
+var (
+	stackJSON    bool
+	stackExplain bool
+)
+
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Show the detected stack and what triggered each detection",
+	Long: `Run stack detection and show each detected technology with its
+category, version and the files that triggered it.
+
+--explain adds the rule behind every piece of evidence, per-package
+results and commands in monorepos, and near misses: files detection looked
+at without recognising anything, such as a compose service with an
+unknown image or a dependency named like a known framework
+("react-native" is not React).
+
+--json prints the full result, including evidence and near misses.`,
+	Args: cobra.NoArgs,
+	RunE: runStack,
+}
+
+func init() {
+	stackCmd.Flags().BoolVar(&stackJSON, "json", false, "Print detection results as JSON")
+	stackCmd.Flags().BoolVar(&stackExplain, "explain", false, "Show detection rules, packages and near misses")
+}
+
+// stackReport is the JSON form of `ck stack`.
+type stackReport struct {
+	ProjectRoot string          `json:"projectRoot"`
+	Stack       []stack.Tech    `json:"stack"`
+	Packages    []stack.Package `json:"packages"`
+}
+
+func runStack(cmd *cobra.Command, args []string) error {
+	projectRoot := resolveProjectRoot()
+	packages := stack.DetectPackages(projectRoot)
+	techs := stack.Merge(packages)
+
+	if stackJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stackReport{ProjectRoot: projectRoot, Stack: techs, Packages: packages})
+	}
+
+	fmt.Println(banner())
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  Project: %s", projectRoot)))
+
+	if len(techs) == 0 {
+		fmt.Println()
+		fmt.Println(dimStyle.Render("  No stack detected. Add dependency files and re-run."))
+	} else {
+		printStackTable(techs)
+	}
+
+	var misses []stack.NearMiss
+	for _, pkg := range packages {
+		misses = append(misses, pkg.NearMisses...)
+	}
+
+	if !stackExplain {
+		if len(misses) > 0 {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  %d near miss(es); run 'ck stack --explain' for details", len(misses))))
+		}
+		fmt.Println()
+		return nil
+	}
+
+	if len(techs) > 0 {
+		fmt.Println(sectionHeader("EVIDENCE"))
+		for _, t := range techs {
+			fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(t.Name)))
+			for _, ev := range t.Evidence {
+				fmt.Println(fmt.Sprintf("    %s %s %s", arrow, infoStyle.Render(ev.File), dimStyle.Render(ev.Rule)))
+			}
+		}
+	}
+
+	if len(packages) > 1 || !packages[0].Commands.IsEmpty() {
+		fmt.Println(sectionHeader("PACKAGES"))
+		for _, pkg := range packages {
+			names := make([]string, len(pkg.Techs))
+			for i, t := range pkg.Techs {
+				names[i] = t.Name
+			}
+			fmt.Println(fmt.Sprintf("  %s %s %s", bullet, accentStyle.Render(pkg.Path), dimStyle.Render(strings.Join(names, ", "))))
+			for _, c := range []struct{ Name, Run string }{
+				{"test", pkg.Commands.Test}, {"lint", pkg.Commands.Lint}, {"build", pkg.Commands.Build},
+			} {
+				if c.Run != "" {
+					fmt.Println(dimStyle.Render(fmt.Sprintf("      %-5s  %s", c.Name, c.Run)))
+				}
+			}
+		}
+	}
+
+	fmt.Println(sectionHeader("NEAR MISSES"))
+	if len(misses) == 0 {
+		fmt.Println(dimStyle.Render("    (none)"))
+	}
+	for _, m := range misses {
+		fmt.Println(fmt.Sprintf("  %s %s %s", warnStyle.Render("!"), infoStyle.Render(m.File), dimStyle.Render(m.Reason)))
+	}
+	fmt.Println()
+	return nil
+}
+
+// printStackTable lists technologies with their category, version and the
+// files that triggered them.
+func printStackTable(techs []stack.Tech) {
+	fmt.Println(sectionHeader("DETECTED STACK"))
+
+	rows := make([][]string, 0, len(techs))
+	for _, t := range techs {
+		var files []string
+		seen := make(map[string]bool)
+		for _, ev := range t.Evidence {
+			if !seen[ev.File] {
+				seen[ev.File] = true
+				files = append(files, ev.File)
+			}
+		}
+		rows = append(rows, []string{
+			checkMark,
+			lipgloss.NewStyle().Foreground(white).Bold(true).Render(t.Name),
+			dimStyle.Render(t.Category),
+			infoStyle.Render(t.Version),
+			dimStyle.Render(strings.Join(files, ", ")),
+		})
+	}
+
+	tbl := table.New().
+		Border(lipgloss.HiddenBorder()).
+		Headers(
+			"",
+			tableHeaderStyle.Render("Name"),
+			tableHeaderStyle.Render("Category"),
+			tableHeaderStyle.Render("Version"),
+			tableHeaderStyle.Render("Files"),
+		).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			s := lipgloss.NewStyle().PaddingRight(2)
+			if col == 0 {
+				s = s.PaddingLeft(4).Width(3)
+			}
+			return s
+		})
+
+	fmt.Println(tbl)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,13 +21,17 @@ var syncCmd = &cobra.Command{
 	Long: `Sync updates installed components from the template catalog.
 
 Only components that are already installed are updated — no new components
-are added. After updating, the docs-index is refreshed if stale.`,
+are added. After updating, the docs-index is refreshed if stale and the
+managed sections of CLAUDE.md are recomposed; text outside the ck markers
+is left untouched.`,
 	RunE: runSync,
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
 	tmplDir := resolveTemplateDir()
 	targetDir := resolveTarget()
+	// No prompts inside the spinner: missing variables fail the component
+	setupTransforms(false)
 
 	fmt.Println(banner())
 
@@ -35,6 +41,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	var updated int
 	var syncErr error
+	var failed []string
 
 	action := func() {
 		// Get installed components
@@ -55,10 +62,14 @@ func runSync(cmd *cobra.Command, args []string) error {
 		for _, cat := range installed {
 			for _, comp := range cat.Components {
 				err := catalog.CopyComponent(tmplDir, targetDir, cat.Name, comp.Name)
-				if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
 					// Skip components not in template (user-created)
 					continue
 				}
+				if err != nil {
+					failed = append(failed, fmt.Sprintf("%s/%s: %v", cat.Name, comp.Name, err))
+					continue
+				}
 				updated++
 			}
 		}
@@ -76,19 +87,23 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Updated %d components", updated))))
+	for _, f := range failed {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("  "+f))
+	}
 
 	// Refresh docs-index
 	projectRoot := filepath.Dir(targetDir)
 	if strings.HasSuffix(targetDir, ".claude") {
-		stale, reason := docsindex.IsStale(projectRoot)
+		docsOpts := docsOptions(projectRoot)
+		stale, reason := docsindex.IsStale(projectRoot, docsOpts)
 		if stale {
 			fmt.Println(warnStyle.Render(fmt.Sprintf("  %s Docs-index needs refresh: %s", bullet, reason)))
 
-			var techs []string
+			var result *docsindex.Result
 			var docsErr error
 
 			docsAction := func() {
-				techs, docsErr = docsindex.Generate(projectRoot)
+				result, docsErr = docsindex.Generate(projectRoot, docsOpts)
 			}
 
 			if err := spinner.New().
@@ -101,12 +116,21 @@ func runSync(cmd *cobra.Command, args []string) error {
 			if docsErr != nil {
 				fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  Docs refresh failed: %v", docsErr)))
 			} else {
-				fmt.Println(fmt.Sprintf("  %s %s", checkMark, infoStyle.Render(fmt.Sprintf("Docs-index refreshed (stack: %s)", strings.Join(techs, ", ")))))
+				fmt.Println(fmt.Sprintf("  %s %s", checkMark, infoStyle.Render(fmt.Sprintf("Docs-index refreshed (stack: %s)", strings.Join(result.Stack, ", ")))))
+				if len(result.Trimmed) > 0 {
+					fmt.Println(warnStyle.Render(fmt.Sprintf("    %s Trimmed to fit %d tokens: %s", bullet, result.MaxTokens, strings.Join(result.Trimmed, ", "))))
+				}
 			}
 		} else {
 			fmt.Println(fmt.Sprintf("  %s %s", checkMark, dimStyle.Render("Docs-index is up to date")))
 		}
 	}
 
+	// Recompose CLAUDE.md managed sections (team roles, fragments, docs import)
+	refreshClaudeMD(tmplDir, targetDir)
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d component(s) could not be synced", len(failed))
+	}
 	return nil
 }
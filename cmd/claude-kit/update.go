@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+)
+
+// updateTarget is an installed external component to update.
+type updateTarget struct {
+	kind       string
+	name       string
+	provenance *catalog.Provenance
+}
+
+// runUpdate re-fetches external components at the latest commit of their
+// recorded ref and, after showing the diff, replaces the installed copy.
+// With no args every component with a provenance record is checked;
+// otherwise args are "<type> <name>...".
+func runUpdate(targetDir string, args []string) error {
+	targets, err := updateTargets(targetDir, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println(dimStyle.Render("  No externally installed components to update."))
+		return nil
+	}
+
+	failed := 0
+	for _, t := range targets {
+		if err := updateComponent(targetDir, t); err != nil {
+			failed++
+			fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s/%s: %v", t.kind, t.name, err)))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d component(s) could not be updated", failed)
+	}
+	return nil
+}
+
+func updateTargets(targetDir string, args []string) ([]updateTarget, error) {
+	if len(args) > 0 {
+		k, ok := catalog.LookupKind(normalizeType(args[0]))
+		if !ok || len(args) < 2 {
+			return nil, fmt.Errorf("usage: ck add --update [<type> <name>...]")
+		}
+		var targets []updateTarget
+		for _, name := range args[1:] {
+			p, err := catalog.ReadProvenance(targetDir, k.Name(), name)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("%s/%s has no provenance record; only external installs can be updated", k.Name(), name)
+			}
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, updateTarget{kind: k.Name(), name: name, provenance: p})
+		}
+		return targets, nil
+	}
+
+	categories, err := catalog.GetInstalled(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	var targets []updateTarget
+	for _, cat := range categories {
+		for _, c := range cat.Components {
+			if p, err := catalog.ReadProvenance(targetDir, c.Type, c.Name); err == nil {
+				targets = append(targets, updateTarget{kind: c.Type, name: c.Name, provenance: p})
+			}
+		}
+	}
+	return targets, nil
+}
+
+func updateComponent(targetDir string, t updateTarget) error {
+	old := t.provenance
+	if old.Repo == "" && old.Archive == "" {
+		return fmt.Errorf("no repository or archive recorded; reinstall it to enable updates")
+	}
+	rel := t.kind + "/" + t.name
+	loc := external.Location{Repo: old.Repo, Archive: old.Archive, Ref: old.Ref, Path: old.Path}
+
+	fmt.Println(infoStyle.Render(fmt.Sprintf("  %s Checking %s (%s)...", bullet, rel, loc)))
+	ctx := context.Background()
+	q, err := external.FetchLocation(ctx, t.kind, t.name, loc)
+	if err != nil {
+		return err
+	}
+	defer q.Discard()
+	q.Provenance.Source, q.Provenance.URL = old.Source, old.URL
+
+	if q.Provenance.Commit != "" && q.Provenance.Commit == old.Commit {
+		fmt.Println(fmt.Sprintf("  %s %s %s", checkMark, accentStyle.Render(rel), dimStyle.Render("up to date at "+shortSHA(old.Commit))))
+		return nil
+	}
+
+	diff, err := q.Diff(ctx, targetDir)
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	if diff == "" && old.Archive != "" {
+		fmt.Println(fmt.Sprintf("  %s %s %s", checkMark, accentStyle.Render(rel), dimStyle.Render("up to date")))
+		return nil
+	}
+
+	title := fmt.Sprintf("UPDATE %s %s → %s", rel, shortSHA(old.Commit), shortSHA(q.Provenance.Commit))
+	if old.Archive != "" {
+		title = "UPDATE " + rel
+	}
+	fmt.Println(sectionHeader(title))
+	if diff == "" {
+		fmt.Println(dimStyle.Render("  No changes to the component files; recording the new commit."))
+		return q.Install(targetDir)
+	}
+	printDiff(diff)
+
+	findings, err := q.Scan()
+	if err != nil {
+		return err
+	}
+	printRisks(findings)
+	if !addTrust {
+		ok, err := confirmInstall(fmt.Sprintf("Update %s?", rel), findings)
+		if err != nil {
+			return fmt.Errorf("review failed: %w (use --trust to update without review)", err)
+		}
+		if !ok {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  Kept %s%s", rel, atCommit(old.Commit))))
+			return nil
+		}
+	}
+
+	if err := q.Install(targetDir); err != nil {
+		return err
+	}
+	fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("Updated %s%s", rel, atCommit(q.Provenance.Commit)))))
+	return nil
+}
+
+// printDiff prints a unified diff with added and removed lines colored.
+func printDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		line = printable(line)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "diff "):
+			line = accentStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = successStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = errorStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = infoStyle.Render(line)
+		default:
+			line = dimStyle.Render(line)
+		}
+		fmt.Println("  " + line)
+	}
+	fmt.Println()
+}
+
+// atCommit returns " at <short sha>", or "" for archive installs, which
+// have no commit.
+func atCommit(sha string) string {
+	if sha == "" {
+		return ""
+	}
+	return " at " + shortSHA(sha)
+}
+
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "unknown"
+	}
+	return sha[:min(len(sha), 7)]
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+)
+
+// commitRepo writes files (slash paths to contents) into the git
+// repository at dir, creating it on branch main if needed, commits them
+// and returns the commit.
+func commitRepo(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		git("init", "--quiet", "--initial-branch=main")
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "update")
+	return git("rev-parse", "HEAD")
+}
+
+func TestUpdateComponent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trust := addTrust
+	addTrust = true
+	t.Cleanup(func() { addTrust = trust })
+
+	repo, target := t.TempDir(), t.TempDir()
+	first := commitRepo(t, repo, map[string]string{
+		"agents/dba.md": "---\ndescription: Database admin\n---\nv1\n",
+		"README.md":     "# repo\n",
+	})
+	q, err := external.FetchLocation(context.Background(), "agents", "dba", external.Location{Repo: "file://" + repo, Ref: "main", Path: "agents/dba.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Install(target); err != nil {
+		t.Fatal(err)
+	}
+
+	update := func() (catalog.Provenance, string) {
+		t.Helper()
+		p, err := catalog.ReadProvenance(target, "agents", "dba")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := updateComponent(target, updateTarget{kind: "agents", name: "dba", provenance: p}); err != nil {
+			t.Fatal(err)
+		}
+		if p, err = catalog.ReadProvenance(target, "agents", "dba"); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(target, "agents", "dba.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *p, string(data)
+	}
+
+	installed, err := catalog.ReadProvenance(target, "agents", "dba")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Up to date: nothing is reinstalled
+	if p, _ := update(); p != *installed || p.Commit != first {
+		t.Errorf("up to date: provenance = %+v, want %+v", p, *installed)
+	}
+
+	// A new commit that leaves the component alone is still recorded
+	second := commitRepo(t, repo, map[string]string{"README.md": "# repo v2\n"})
+	if p, content := update(); p.Commit != second || !strings.HasSuffix(content, "v1\n") {
+		t.Errorf("empty diff: commit = %s, content %q; want %s and v1", shortSHA(p.Commit), content, shortSHA(second))
+	}
+
+	third := commitRepo(t, repo, map[string]string{"agents/dba.md": "---\ndescription: Database admin\n---\nv2\n"})
+	p, content := update()
+	if p.Commit != third || !strings.HasSuffix(content, "v2\n") {
+		t.Errorf("changed: commit = %s, content %q; want %s and v2", shortSHA(p.Commit), content, shortSHA(third))
+	}
+	if p.Repo != "file://"+repo || p.Ref != "main" || p.Path != "agents/dba.md" {
+		t.Errorf("changed: provenance = %+v", p)
+	}
+
+	err = updateComponent(target, updateTarget{kind: "agents", name: "dba", provenance: &catalog.Provenance{}})
+	if err == nil || !strings.Contains(err.Error(), "no repository or archive") {
+		t.Errorf("err = %v, want no repository or archive", err)
+	}
+}
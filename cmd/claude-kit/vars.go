@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/tmplvars"
+)
+
+var varsCmd = &cobra.Command{
+	Use:   "vars | vars set <name> <value>",
+	Short: "Show or set template variables used by components",
+	Long: `List the template variables referenced by templated components and
+the values they resolve to in this project.
+
+Components opt into rendering with "templated: true" in their frontmatter
+and use Go text/template syntax, e.g. {{.ProjectName}} or {{.TestCommand}}.
+Values come from (later wins): the project directory, the detected stack,
+git config, and .claude/ck-values.json.
+
+Examples:
+  ck vars                               # List variables and resolved values
+  ck vars set TestCommand "make test"   # Store a value in .claude/ck-values.json`,
+	RunE: runVars,
+}
+
+var varsSetCmd = &cobra.Command{
+	Use:   "set <name> <value>",
+	Short: "Set a template variable in .claude/ck-values.json",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := tmplvars.SaveValue(resolveProjectRoot(), args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Println(fmt.Sprintf("  %s %s", checkMark, accentStyle.Render(fmt.Sprintf("%s = %s", args[0], args[1]))))
+		return nil
+	},
+}
+
+func init() {
+	varsCmd.AddCommand(varsSetCmd)
+}
+
+// setupTransforms registers the install-time transforms: template rendering
+// and rule glob rescoping. When interactive, missing template variables are
+// prompted for and saved.
+func setupTransforms(interactive bool) {
+	projectRoot := resolveProjectRoot()
+
+	r := &tmplvars.Renderer{ProjectRoot: projectRoot}
+	if interactive {
+		r.Prompt = promptVariable
+	}
+	catalog.AddTransform(r.Transform)
+
+	g := &catalog.GlobRewriter{ProjectRoot: projectRoot}
+	catalog.AddTransform(g.Transform)
+}
+
+// promptVariable asks for a template variable's value.
+func promptVariable(name string) (string, error) {
+	var value string
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(fmt.Sprintf("Value for template variable %s", name)).
+				Description("Saved to .claude/" + tmplvars.ValuesFile).
+				Value(&value),
+		),
+	).WithTheme(ckTheme())
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func runVars(cmd *cobra.Command, args []string) error {
+	tmplDir := resolveTemplateDir()
+	projectRoot := resolveProjectRoot()
+
+	fmt.Println(banner())
+
+	categories, err := catalog.ScanTemplate(tmplDir)
+	if err != nil {
+		return fmt.Errorf("scanning templates: %w", err)
+	}
+
+	// variable → components that reference it
+	usedBy := make(map[string][]string)
+	for _, cat := range categories {
+		k, _ := catalog.LookupKind(cat.Name)
+		for _, c := range cat.Components {
+			vars := componentVariables(k, c)
+			for _, v := range vars {
+				usedBy[v] = append(usedBy[v], cat.Name+"/"+c.Name)
+			}
+		}
+	}
+
+	resolved := tmplvars.Resolve(projectRoot)
+
+	names := make(map[string]bool)
+	for v := range usedBy {
+		names[v] = true
+	}
+	for _, v := range resolved.Names() {
+		names[v] = true
+	}
+
+	rows := [][]string{}
+	for _, name := range sortedKeys(names) {
+		value := dimStyle.Render("(unset)")
+		source := ""
+		if v, ok := resolved[name]; ok {
+			value = v.Value
+			source = dimStyle.Render(v.Source)
+		} else if len(usedBy[name]) > 0 {
+			value = warnStyle.Render("(unset)")
+		}
+
+		used := dimStyle.Render("-")
+		if refs := usedBy[name]; len(refs) > 0 {
+			sort.Strings(refs)
+			used = strings.Join(refs, ", ")
+		}
+
+		rows = append(rows, []string{accentStyle.Render(name), value, source, used})
+	}
+
+	fmt.Println(sectionHeader("Template variables"))
+	t := table.New().
+		Border(lipgloss.HiddenBorder()).
+		Headers(
+			tableHeaderStyle.Render("Name"),
+			tableHeaderStyle.Render("Value"),
+			tableHeaderStyle.Render("Source"),
+			tableHeaderStyle.Render("Used by"),
+		).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			s := lipgloss.NewStyle().PaddingRight(2)
+			if col == 0 {
+				s = s.PaddingLeft(4)
+			}
+			return s
+		})
+	fmt.Println(t)
+
+	fmt.Println()
+	fmt.Println(dimStyle.Render("  Set a value: ck vars set <name> <value>"))
+	fmt.Println()
+	return nil
+}
+
+// componentVariables returns the variables referenced by a templated
+// component's markdown files.
+func componentVariables(k catalog.Kind, c catalog.Component) []string {
+	if k == nil || !catalog.IsTemplated(c.File) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	_ = filepath.WalkDir(c.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		vars, err := tmplvars.Variables(data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, errorStyle.Render(fmt.Sprintf("  %s/%s: %v", k.Name(), c.Name, err)))
+			return nil
+		}
+		for _, v := range vars {
+			seen[v] = true
+		}
+		return nil
+	})
+	return sortedKeys(seen)
+}
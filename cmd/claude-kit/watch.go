@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/docsindex"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
+)
+
+var (
+	watchDebounce   time.Duration
+	watchNoTemplate bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep docs-index in sync and report template updates as files change",
+	Long: `Watch the project for changes and keep the kit in sync.
+
+Changes to dependency files, lockfiles, stack directories (k8s/, helm/, …)
+and .claude/docs-overrides/ regenerate the docs-index once it is stale.
+Changes in the template directory are reported as updates available for
+installed components (apply them with 'ck sync'); edits to the template's
+stacks/*.yaml are picked up immediately.
+
+Events are debounced (--debounce). Logs go to stderr and no terminal is
+required, so ck watch can run from a devcontainer or a supervisor. Stop it
+with Ctrl-C (SIGINT) or SIGTERM.`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 500*time.Millisecond, "Quiet period before acting on changes")
+	watchCmd.Flags().BoolVar(&watchNoTemplate, "no-template", false, "Do not watch the template directory")
+}
+
+// watcher holds the state of a running `ck watch`.
+type watcher struct {
+	fs          *fsnotify.Watcher
+	log         *log.Logger
+	projectRoot string
+	targetDir   string
+	tmplDir     string // "" when the template is not watched
+	watched     map[string]bool
+
+	changedComponents map[string]bool // "kind/name" awaiting a report
+	definitionsDirty  bool
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("starting file watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	w := &watcher{
+		fs:                fsw,
+		log:               log.New(os.Stderr, "ck watch: ", log.LstdFlags),
+		projectRoot:       resolveProjectRoot(),
+		targetDir:         resolveTarget(),
+		watched:           make(map[string]bool),
+		changedComponents: make(map[string]bool),
+	}
+
+	w.addProjectDirs()
+	w.log.Printf("watching %s (%d directories)", w.projectRoot, len(w.watched))
+
+	if !watchNoTemplate {
+		tmplDir := resolveTemplateDir()
+		if info, err := os.Stat(tmplDir); err == nil && info.IsDir() {
+			w.tmplDir = tmplDir
+			n := w.addTree(tmplDir)
+			w.log.Printf("watching template %s (%d directories)", tmplDir, n)
+		} else {
+			w.log.Printf("template directory %s not found; template updates will not be reported", tmplDir)
+		}
+	}
+
+	// Bring the index up to date before waiting for changes
+	w.checkDocs()
+
+	docsTimer := newStoppedTimer()
+	tmplTimer := newStoppedTimer()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Printf("stopping")
+			return nil
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			w.log.Printf("watch error: %v", err)
+
+		case ev, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Has(fsnotify.Chmod) && !ev.Has(fsnotify.Write) {
+				continue
+			}
+			if w.tmplDir != "" && isWithin(w.tmplDir, ev.Name) {
+				if w.templateEvent(ev) {
+					tmplTimer.Reset(watchDebounce)
+				}
+				continue
+			}
+			if w.projectEvent(ev) {
+				docsTimer.Reset(watchDebounce)
+			}
+
+		case <-docsTimer.C:
+			w.checkDocs()
+
+		case <-tmplTimer.C:
+			w.reportTemplateChanges()
+		}
+	}
+}
+
+// newStoppedTimer returns a timer that only fires after Reset.
+func newStoppedTimer() *time.Timer {
+	t := time.NewTimer(time.Hour)
+	t.Stop()
+	return t
+}
+
+// addProjectDirs watches every project directory detection looks at, plus
+// .claude/docs-overrides. Already-watched directories are skipped.
+func (w *watcher) addProjectDirs() {
+	stack.WalkDirs(w.projectRoot, func(rel string) {
+		w.add(filepath.Join(w.projectRoot, filepath.FromSlash(rel)))
+	})
+	// .claude is skipped by detection; only the overrides matter here
+	w.add(w.targetDir)
+	w.add(filepath.Join(w.targetDir, docsindex.OverridesDir))
+}
+
+// addTree watches dir and all its subdirectories and returns how many were added.
+func (w *watcher) addTree(dir string) int {
+	n := 0
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && (d.Name() == ".git" || stack.IsSkippedDir(d.Name())) {
+			return filepath.SkipDir
+		}
+		if w.add(path) {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+func (w *watcher) add(dir string) bool {
+	if w.watched[dir] {
+		return false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return false
+	}
+	if err := w.fs.Add(dir); err != nil {
+		w.log.Printf("cannot watch %s: %v", dir, err)
+		return false
+	}
+	w.watched[dir] = true
+	return true
+}
+
+// projectEvent reports whether a project change may affect the docs-index:
+// a detection input (manifest, lockfile, config or stack marker, see
+// stack.IsDetectionInput), a removed directory or a docs override. New
+// directories are watched as they appear.
+func (w *watcher) projectEvent(ev fsnotify.Event) bool {
+	rel, err := filepath.Rel(w.projectRoot, ev.Name)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	// ck's own output would otherwise retrigger the watch
+	if strings.HasPrefix(rel, ".claude/") || rel == ".claude" {
+		overrides := ".claude/" + docsindex.OverridesDir
+		if rel == overrides && ev.Has(fsnotify.Create) {
+			w.add(ev.Name)
+		}
+		return rel == overrides || strings.HasPrefix(rel, overrides+"/")
+	}
+	for _, seg := range strings.Split(rel, "/") {
+		if seg == ".git" || stack.IsSkippedDir(seg) {
+			return false
+		}
+	}
+
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			w.addProjectDirs()
+		}
+	}
+	if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+		if w.watched[ev.Name] {
+			// A removed directory may have held a package
+			delete(w.watched, ev.Name)
+			return true
+		}
+	}
+	return stack.IsDetectionInput(rel)
+}
+
+// checkDocs regenerates the docs-index if it is stale.
+func (w *watcher) checkDocs() {
+	opts := docsOptions(w.projectRoot)
+	stale, reason := docsindex.IsStale(w.projectRoot, opts)
+	if !stale {
+		return
+	}
+
+	result, err := docsindex.Generate(w.projectRoot, opts)
+	if err != nil {
+		w.log.Printf("regenerating docs-index (%s) failed: %v", reason, err)
+		return
+	}
+	w.log.Printf("regenerated docs-index: %s (stack: %s; ~%d tokens)",
+		reason, strings.Join(result.Stack, ", "), result.EstimatedTokens)
+	if len(result.Trimmed) > 0 {
+		w.log.Printf("trimmed to fit %d tokens: %s", result.MaxTokens, strings.Join(result.Trimmed, ", "))
+	}
+
+	if _, err := os.Stat(filepath.Join(w.targetDir, catalog.ClaudeMDFile)); err == nil {
+		if err := catalog.ComposeClaudeMD(resolveTemplateDir(), w.targetDir); err != nil {
+			w.log.Printf("updating CLAUDE.md: %v", err)
+		}
+	}
+}
+
+// templateEvent records a template change and reports whether it needs
+// acting on.
+func (w *watcher) templateEvent(ev fsnotify.Event) bool {
+	rel, err := filepath.Rel(w.tmplDir, ev.Name)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			w.addTree(ev.Name)
+		}
+	}
+
+	if parts[0] == stack.StacksDirName {
+		w.definitionsDirty = true
+		return true
+	}
+	if len(parts) < 2 {
+		return false
+	}
+	k, ok := catalog.LookupKind(parts[0])
+	if !ok {
+		return false
+	}
+	w.changedComponents[k.Name()+"/"+w.templateComponent(k, parts)] = true
+	return true
+}
+
+// templateComponent names the component a template path belongs to, the
+// way the kind's Scan names it: the directory holding SKILL.md for skills
+// (which may be nested, e.g. security/pentest-web), the path below the kind
+// directory without .md otherwise.
+func (w *watcher) templateComponent(k catalog.Kind, parts []string) string {
+	if k.Name() != "skills" {
+		return strings.TrimSuffix(strings.Join(parts[1:], "/"), ".md")
+	}
+	for j := len(parts); j > 1; j-- {
+		name := strings.Join(parts[1:j], "/")
+		if _, err := os.Stat(filepath.Join(k.Path(w.tmplDir, name), "SKILL.md")); err == nil {
+			return name
+		}
+	}
+	return parts[1]
+}
+
+// reportTemplateChanges logs updates available for installed components
+// and reloads stack definitions when they changed.
+func (w *watcher) reportTemplateChanges() {
+	if w.definitionsDirty {
+		w.definitionsDirty = false
+		defs, err := stack.LoadDefinitions(filepath.Join(w.tmplDir, stack.StacksDirName))
+		if err != nil {
+			// Invalid files are skipped; the valid ones still load
+			w.log.Printf("stack definitions: %v", err)
+		}
+		stack.ResetDefinitions()
+		stack.RegisterDefinitions(defs...)
+		w.log.Printf("reloaded %d stack definition(s)", len(defs))
+		w.checkDocs()
+	}
+
+	var updates []string
+	for key := range w.changedComponents {
+		kind, name, _ := strings.Cut(key, "/")
+		if catalog.IsInstalled(w.targetDir, kind, name) {
+			updates = append(updates, key)
+		}
+	}
+	clear(w.changedComponents)
+	if len(updates) == 0 {
+		return
+	}
+	sort.Strings(updates)
+	w.log.Printf("template updates available for %d installed component(s): %s (run 'ck sync' to apply)",
+		len(updates), strings.Join(updates, ", "))
+}
+
+// isWithin reports whether path is dir or inside it.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -12,10 +12,12 @@ import (
 
 // Component represents a single template component (agent, skill, command, rule).
 type Component struct {
-	Type        string // "agents", "skills", "commands", "rules"
-	Name        string // e.g. "backend", "security/pentest-web"
-	Description string // extracted from YAML frontmatter
-	Path        string // absolute path in template dir
+	Type        string     // registered kind name, e.g. "agents", "skills"
+	Name        string     // e.g. "backend", "security/pentest-web"
+	Description string     // extracted from YAML frontmatter
+	Path        string     // absolute path in template dir
+	File        string     // main markdown file (SKILL.md for skills)
+	When        Conditions // applicability conditions from frontmatter
 }
 
 // Category groups components by type.
@@ -29,33 +31,29 @@ func ScanTemplate(templateDir string) ([]Category, error) {
 	if _, err := os.Stat(templateDir); err != nil {
 		return nil, fmt.Errorf("template directory not found: %s", templateDir)
 	}
+	return scanKinds(templateDir), nil
+}
 
-	types := []string{"agents", "skills", "commands", "rules"}
+// scanKinds scans every registered kind under baseDir.
+func scanKinds(baseDir string) []Category {
 	var categories []Category
 
-	for _, t := range types {
-		dir := filepath.Join(templateDir, t)
+	for _, k := range kinds {
+		dir := filepath.Join(baseDir, k.Name())
 		if _, err := os.Stat(dir); err != nil {
 			continue
 		}
 
-		var components []Component
-		switch t {
-		case "skills":
-			components = scanSkills(dir, t)
-		case "agents", "commands", "rules":
-			components = scanMarkdownDir(dir, t)
-		}
-
+		components := k.Scan(dir)
 		if len(components) > 0 {
 			sort.Slice(components, func(i, j int) bool {
 				return components[i].Name < components[j].Name
 			})
-			categories = append(categories, Category{Name: t, Components: components})
+			categories = append(categories, Category{Name: k.Name(), Components: components})
 		}
 	}
 
-	return categories, nil
+	return categories
 }
 
 // scanSkills handles the nested skill directory structure.
@@ -85,6 +83,8 @@ func scanSkills(dir, typeName string) []Component {
 				Name:        name,
 				Description: desc,
 				Path:        skillDir,
+				File:        skillFile,
+				When:        ExtractConditions(skillFile),
 			})
 		}
 
@@ -106,6 +106,8 @@ func scanSkills(dir, typeName string) []Component {
 					Name:        subName,
 					Description: desc,
 					Path:        filepath.Join(skillDir, sub.Name()),
+					File:        subSkillFile,
+					When:        ExtractConditions(subSkillFile),
 				})
 			}
 		}
@@ -137,6 +139,8 @@ func scanMarkdownDir(dir, typeName string) []Component {
 			Name:        name,
 			Description: desc,
 			Path:        filePath,
+			File:        filePath,
+			When:        ExtractConditions(filePath),
 		})
 	}
 
@@ -145,6 +149,12 @@ func scanMarkdownDir(dir, typeName string) []Component {
 
 // ExtractDescription reads the YAML frontmatter description from a file.
 func ExtractDescription(path string) string {
+	return ExtractField(path, "description")
+}
+
+// ExtractField reads a scalar top-level key from a file's YAML frontmatter.
+// Surrounding quotes are removed. Returns "" if the key is absent.
+func ExtractField(path, key string) string {
 	f, err := os.Open(path)
 	if err != nil {
 		return ""
@@ -153,6 +163,7 @@ func ExtractDescription(path string) string {
 
 	scanner := bufio.NewScanner(f)
 	inFrontmatter := false
+	prefix := key + ":"
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -165,14 +176,14 @@ func ExtractDescription(path string) string {
 			continue
 		}
 
-		if inFrontmatter && strings.HasPrefix(line, "description:") {
-			desc := strings.TrimPrefix(line, "description:")
-			desc = strings.TrimSpace(desc)
+		if inFrontmatter && strings.HasPrefix(line, prefix) {
+			value := strings.TrimPrefix(line, prefix)
+			value = strings.TrimSpace(value)
 			// Remove surrounding quotes if present
-			if len(desc) >= 2 && ((desc[0] == '"' && desc[len(desc)-1] == '"') || (desc[0] == '\'' && desc[len(desc)-1] == '\'')) {
-				desc = desc[1 : len(desc)-1]
+			if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'')) {
+				value = value[1 : len(value)-1]
 			}
-			return desc
+			return value
 		}
 	}
 
@@ -249,102 +260,46 @@ func GetInstalled(targetDir string) ([]Category, error) {
 	if _, err := os.Stat(targetDir); err != nil {
 		return nil, nil
 	}
-
-	types := []string{"agents", "skills", "commands", "rules"}
-	var categories []Category
-
-	for _, t := range types {
-		dir := filepath.Join(targetDir, t)
-		if _, err := os.Stat(dir); err != nil {
-			continue
-		}
-
-		var components []Component
-		switch t {
-		case "skills":
-			components = scanSkills(dir, t)
-		case "agents", "commands", "rules":
-			components = scanMarkdownDir(dir, t)
-		}
-
-		if len(components) > 0 {
-			sort.Slice(components, func(i, j int) bool {
-				return components[i].Name < components[j].Name
-			})
-			categories = append(categories, Category{Name: t, Components: components})
-		}
-	}
-
-	return categories, nil
+	return scanKinds(targetDir), nil
 }
 
-// CopyComponent copies a component from template to target directory.
+// CopyComponent copies a component from template to target directory. A
+// provenance record left by an external install of the same name is
+// removed, as the component now comes from the template.
 func CopyComponent(templateDir, targetDir, compType, name string) error {
-	switch compType {
-	case "skills":
-		return copySkill(templateDir, targetDir, name)
-	case "agents", "commands", "rules":
-		return copyMarkdown(templateDir, targetDir, compType, name)
-	default:
+	k, ok := LookupKind(compType)
+	if !ok {
 		return fmt.Errorf("unknown component type: %s", compType)
 	}
-}
-
-func copySkill(templateDir, targetDir, name string) error {
-	srcDir := filepath.Join(templateDir, "skills", name)
-	if _, err := os.Stat(srcDir); err != nil {
-		return fmt.Errorf("skill not found: %s", name)
-	}
-
-	dstDir := filepath.Join(targetDir, "skills", name)
-	if err := os.MkdirAll(filepath.Dir(dstDir), 0o755); err != nil {
+	if err := k.Copy(templateDir, targetDir, name); err != nil {
 		return err
 	}
-
-	return copyDir(srcDir, dstDir)
-}
-
-func copyMarkdown(templateDir, targetDir, compType, name string) error {
-	srcFile := filepath.Join(templateDir, compType, name+".md")
-	if _, err := os.Stat(srcFile); err != nil {
-		return fmt.Errorf("%s not found: %s", compType, name)
-	}
-
-	dstDir := filepath.Join(targetDir, compType)
-	if err := os.MkdirAll(dstDir, 0o755); err != nil {
+	if err := os.Remove(ProvenancePath(k, targetDir, name)); err != nil && !os.IsNotExist(err) {
 		return err
 	}
-
-	return copyFile(srcFile, filepath.Join(dstDir, name+".md"))
+	return nil
 }
 
 // RemoveComponent removes a component from the target directory.
 func RemoveComponent(targetDir, compType, name string) error {
-	switch compType {
-	case "skills":
-		dir := filepath.Join(targetDir, "skills", name)
-		return os.RemoveAll(dir)
-	case "agents", "commands", "rules":
-		file := filepath.Join(targetDir, compType, name+".md")
-		return os.Remove(file)
-	default:
+	k, ok := LookupKind(compType)
+	if !ok {
 		return fmt.Errorf("unknown component type: %s", compType)
 	}
+	if err := k.Remove(targetDir, name); err != nil {
+		return err
+	}
+	_ = os.Remove(ProvenancePath(k, targetDir, name))
+	return nil
 }
 
 // IsInstalled checks if a specific component is installed.
 func IsInstalled(targetDir, compType, name string) bool {
-	switch compType {
-	case "skills":
-		skillFile := filepath.Join(targetDir, "skills", name, "SKILL.md")
-		_, err := os.Stat(skillFile)
-		return err == nil
-	case "agents", "commands", "rules":
-		file := filepath.Join(targetDir, compType, name+".md")
-		_, err := os.Stat(file)
-		return err == nil
+	k, ok := LookupKind(compType)
+	if !ok {
+		return false
 	}
-	return false
+	return k.IsInstalled(targetDir, name)
 }
 
 // FindReferencingAgents returns agent names that reference the given skill.
@@ -372,18 +327,17 @@ func FindReferencingAgents(targetDir, skillName string) []string {
 	return refs
 }
 
-// CopyBaseFiles copies CLAUDE.md and settings.json from template to target.
+// CopyBaseFiles installs CLAUDE.md and settings.json from template to target.
+// CLAUDE.md is seeded from the template only when missing; afterwards its
+// managed sections are recomposed and user content is never overwritten.
 func CopyBaseFiles(templateDir, targetDir string) error {
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
 		return err
 	}
 
-	// Copy CLAUDE.md (lives one level up from the .claude/ dir in template)
-	claudeMd := filepath.Join(templateDir, "CLAUDE.md")
-	if _, err := os.Stat(claudeMd); err == nil {
-		if err := copyFile(claudeMd, filepath.Join(targetDir, "CLAUDE.md")); err != nil {
-			return err
-		}
+	// Seed CLAUDE.md on first install, then refresh its managed sections
+	if err := ComposeClaudeMD(templateDir, targetDir); err != nil {
+		return err
 	}
 
 	// Copy settings.json
@@ -450,32 +404,3 @@ func copyFile(src, dst string) error {
 	}
 	return os.WriteFile(dst, data, 0o644)
 }
-
-// copyDir recursively copies a directory.
-func copyDir(src, dst string) error {
-	if err := os.MkdirAll(dst, 0o755); err != nil {
-		return err
-	}
-
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
-	}
-
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		if entry.IsDir() {
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
@@ -0,0 +1,192 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ClaudeMDFile is the project memory file inside the .claude/ directory.
+	ClaudeMDFile = "CLAUDE.md"
+	// FragmentsDirName holds per-component CLAUDE.md fragments in the template,
+	// laid out as fragments/<kind>/<name>.md.
+	FragmentsDirName = "fragments"
+
+	sectionBegin = "<!-- ck:begin "
+	sectionEnd   = "<!-- ck:end "
+	markerClose  = " -->"
+)
+
+// Section is a block of CLAUDE.md managed by ck. It is written between
+// begin/end markers and rewritten in place on every install, remove and sync.
+type Section struct {
+	ID   string // e.g. "team-roles", "skills/security"
+	Body string
+}
+
+// ManagedSections computes the sections contributed by the components
+// installed in targetDir:
+//   - "team-roles" lists installed agents
+//   - "<kind>/<name>" for every installed component with a template fragment
+//   - "docs-index" imports docs-index.md once it has been generated
+func ManagedSections(templateDir, targetDir string) []Section {
+	var sections []Section
+
+	installed, _ := GetInstalled(targetDir)
+
+	for _, cat := range installed {
+		if cat.Name != "agents" {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString("## Team roles\n\n")
+		for _, c := range cat.Components {
+			if c.Description != "" {
+				sb.WriteString(fmt.Sprintf("- **%s** — %s\n", c.Name, c.Description))
+			} else {
+				sb.WriteString(fmt.Sprintf("- **%s**\n", c.Name))
+			}
+		}
+		sections = append(sections, Section{ID: "team-roles", Body: sb.String()})
+	}
+
+	for _, cat := range installed {
+		for _, c := range cat.Components {
+			fragment := filepath.Join(templateDir, FragmentsDirName, cat.Name, c.Name+".md")
+			data, err := os.ReadFile(fragment)
+			if err != nil {
+				continue
+			}
+			sections = append(sections, Section{ID: cat.Name + "/" + c.Name, Body: string(data)})
+		}
+	}
+
+	// Imports are resolved relative to CLAUDE.md, which lives next to
+	// docs-index.md in .claude/.
+	if _, err := os.Stat(filepath.Join(targetDir, "docs-index.md")); err == nil {
+		sections = append(sections, Section{ID: "docs-index", Body: "## Stack notes\n\n@docs-index.md\n"})
+	}
+
+	return sections
+}
+
+// ComposeClaudeMD rewrites the managed sections of targetDir/CLAUDE.md from
+// the currently installed components. Content outside the markers is left
+// untouched. If CLAUDE.md does not exist it is seeded from the template.
+func ComposeClaudeMD(templateDir, targetDir string) error {
+	path := filepath.Join(targetDir, ClaudeMDFile)
+
+	existing, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", ClaudeMDFile, err)
+	}
+
+	base := existing
+	if !exists {
+		base, _ = os.ReadFile(filepath.Join(templateDir, ClaudeMDFile))
+	}
+
+	content := ApplySections(string(base), ManagedSections(templateDir, targetDir))
+	if exists && content == string(existing) {
+		return nil
+	}
+
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// ApplySections replaces every managed block in content with the matching
+// section, drops blocks whose section is no longer present, and appends new
+// sections at the end. Text outside the markers is preserved byte for byte.
+// An unterminated begin marker is treated as user content.
+func ApplySections(content string, sections []Section) string {
+	byID := make(map[string]Section, len(sections))
+	for _, s := range sections {
+		byID[s.ID] = s
+	}
+	written := make(map[string]bool)
+
+	var out string
+	rest := content
+	for {
+		start := strings.Index(rest, sectionBegin)
+		if start < 0 {
+			break
+		}
+		id, afterBegin, ok := parseMarker(rest[start:], sectionBegin)
+		if !ok {
+			out += rest[:start+len(sectionBegin)]
+			rest = rest[start+len(sectionBegin):]
+			continue
+		}
+		endMarker := sectionEnd + id + markerClose
+		end := strings.Index(rest[start+afterBegin:], endMarker)
+		if end < 0 {
+			out += rest[:start+afterBegin]
+			rest = rest[start+afterBegin:]
+			continue
+		}
+		blockEnd := start + afterBegin + end + len(endMarker)
+
+		out += rest[:start]
+		rest = rest[blockEnd:]
+
+		if s, ok := byID[id]; ok && !written[id] {
+			out += renderSection(s)
+			written[id] = true
+		} else {
+			// Section removed: swallow the newline that followed the block,
+			// and the blank separator line if one already precedes it.
+			rest = strings.TrimPrefix(rest, "\n")
+			if strings.HasSuffix(out, "\n\n") {
+				if rest == "" {
+					out = out[:len(out)-1]
+				} else {
+					rest = strings.TrimPrefix(rest, "\n")
+				}
+			}
+		}
+	}
+	result := out + rest
+	for _, s := range sections {
+		if written[s.ID] {
+			continue
+		}
+		if result != "" && !strings.HasSuffix(result, "\n\n") {
+			if strings.HasSuffix(result, "\n") {
+				result += "\n"
+			} else {
+				result += "\n\n"
+			}
+		}
+		result += renderSection(s) + "\n"
+	}
+
+	return result
+}
+
+// renderSection wraps a section body in its begin/end markers.
+func renderSection(s Section) string {
+	body := strings.TrimRight(s.Body, "\n")
+	return sectionBegin + s.ID + markerClose + "\n" + body + "\n" + sectionEnd + s.ID + markerClose
+}
+
+// parseMarker reads the section ID from a marker at the start of s and
+// returns it along with the marker's length.
+func parseMarker(s, prefix string) (string, int, bool) {
+	closeIdx := strings.Index(s, markerClose)
+	lineEnd := strings.IndexByte(s, '\n')
+	if closeIdx < 0 || (lineEnd >= 0 && closeIdx > lineEnd) {
+		return "", 0, false
+	}
+	id := strings.TrimSpace(s[len(prefix):closeIdx])
+	if id == "" {
+		return "", 0, false
+	}
+	return id, closeIdx + len(markerClose), true
+}
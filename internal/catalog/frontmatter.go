@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conditions restrict a component to projects matching them, declared in
+// frontmatter as e.g. `when: { stack: [nextjs, react] }`.
+type Conditions struct {
+	Stack []string `yaml:"stack"` // any of these detected technologies
+}
+
+// IsEmpty reports whether no conditions are declared.
+func (c Conditions) IsEmpty() bool {
+	return len(c.Stack) == 0
+}
+
+// Matches reports whether the conditions hold for the detected stack names.
+// Empty conditions always match.
+func (c Conditions) Matches(stackNames []string) bool {
+	if len(c.Stack) == 0 {
+		return true
+	}
+	detected := make(map[string]bool, len(stackNames))
+	for _, n := range stackNames {
+		detected[strings.ToLower(n)] = true
+	}
+	for _, want := range c.Stack {
+		if detected[strings.ToLower(want)] {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the conditions for display, e.g. "stack: nextjs|react".
+func (c Conditions) String() string {
+	if len(c.Stack) == 0 {
+		return ""
+	}
+	return "stack: " + strings.Join(c.Stack, "|")
+}
+
+// SplitFrontmatter separates a leading YAML frontmatter block from the body.
+// ok is false when data does not start with a "---" line.
+func SplitFrontmatter(data []byte) (front, body []byte, ok bool) {
+	if !bytes.HasPrefix(data, []byte("---\n")) && !bytes.HasPrefix(data, []byte("---\r\n")) {
+		return nil, data, false
+	}
+	rest := data[bytes.IndexByte(data, '\n')+1:]
+
+	offset := 0
+	for offset <= len(rest) {
+		lineEnd := bytes.IndexByte(rest[offset:], '\n')
+		var line []byte
+		if lineEnd < 0 {
+			line = rest[offset:]
+		} else {
+			line = rest[offset : offset+lineEnd]
+		}
+		if strings.TrimSpace(string(line)) == "---" {
+			bodyStart := offset + len(line)
+			if lineEnd >= 0 {
+				bodyStart++
+			}
+			return rest[:offset], rest[bodyStart:], true
+		}
+		if lineEnd < 0 {
+			break
+		}
+		offset += lineEnd + 1
+	}
+	return nil, data, false
+}
+
+// ReadFrontmatter parses a file's YAML frontmatter into out.
+// Files without frontmatter leave out untouched.
+func ReadFrontmatter(path string, out any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	front, _, ok := SplitFrontmatter(data)
+	if !ok {
+		return nil
+	}
+	return yaml.Unmarshal(front, out)
+}
+
+// ExtractConditions reads the `when:` block from a file's frontmatter.
+// Unparseable frontmatter yields no conditions.
+func ExtractConditions(path string) Conditions {
+	var fm struct {
+		When Conditions `yaml:"when"`
+	}
+	if err := ReadFrontmatter(path, &fm); err != nil {
+		return Conditions{}
+	}
+	return fm.When
+}
@@ -0,0 +1,176 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
+)
+
+// RuleGlobsFile holds per-project rule glob overrides inside .claude/, e.g.
+//
+//	{ "api": ["internal/transport/**"], "testing": ["**/*_test.go"] }
+const RuleGlobsFile = "rule-globs.json"
+
+// globKeys are the frontmatter keys that scope a rule to files.
+var globKeys = []string{"globs", "paths"}
+
+// LoadGlobOverrides reads .claude/rule-globs.json. A missing file yields nil.
+func LoadGlobOverrides(targetDir string) (map[string][]string, error) {
+	data, err := os.ReadFile(filepath.Join(targetDir, RuleGlobsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", RuleGlobsFile, err)
+	}
+
+	var overrides map[string][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", RuleGlobsFile, err)
+	}
+	return overrides, nil
+}
+
+// ExtractGlobs returns the globs/paths a rule file is scoped to.
+func ExtractGlobs(path string) []string {
+	var fm struct {
+		Globs any `yaml:"globs"`
+		Paths any `yaml:"paths"`
+	}
+	if err := ReadFrontmatter(path, &fm); err != nil {
+		return nil
+	}
+	if globs := globList(fm.Globs); len(globs) > 0 {
+		return globs
+	}
+	return globList(fm.Paths)
+}
+
+// globList normalises a YAML list or a comma-separated string.
+func globList(v any) []string {
+	var out []string
+	switch val := v.(type) {
+	case string:
+		for _, g := range strings.Split(val, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				out = append(out, g)
+			}
+		}
+	case []any:
+		for _, g := range val {
+			if s, ok := g.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// SetFrontmatterList replaces the value of a top-level frontmatter key with
+// values, keeping the key's original style (block list, flow list or
+// comma-separated string). Everything else in the file is left as-is.
+// Returns false if the key is not present.
+func SetFrontmatterList(data []byte, key string, values []string) ([]byte, bool) {
+	front, body, ok := SplitFrontmatter(data)
+	if !ok {
+		return data, false
+	}
+
+	// Keep the file's line endings
+	eol := "\n"
+	if bytes.HasPrefix(data, []byte("---\r\n")) {
+		eol = "\r\n"
+	}
+
+	lines := strings.SplitAfter(string(front), "\n")
+	prefix := key + ":"
+	for i, line := range lines {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		inline := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		end := i + 1
+		if inline == "" {
+			// Block list: consume indented "- item" lines
+			for end < len(lines) {
+				t := strings.TrimSpace(lines[end])
+				if t == "" || !(strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t") || strings.HasPrefix(t, "- ")) {
+					break
+				}
+				end++
+			}
+		}
+
+		var repl string
+		switch {
+		case inline == "":
+			var sb strings.Builder
+			sb.WriteString(prefix + eol)
+			for _, v := range values {
+				sb.WriteString(fmt.Sprintf("  - %q%s", v, eol))
+			}
+			repl = sb.String()
+		case strings.HasPrefix(inline, "["):
+			quoted := make([]string, len(values))
+			for j, v := range values {
+				quoted[j] = fmt.Sprintf("%q", v)
+			}
+			repl = fmt.Sprintf("%s [%s]%s", prefix, strings.Join(quoted, ", "), eol)
+		default:
+			repl = fmt.Sprintf("%s %s%s", prefix, strings.Join(values, ", "), eol)
+		}
+
+		out := strings.Join(lines[:i], "") + repl + strings.Join(lines[end:], "")
+		return []byte("---" + eol + out + "---" + eol + string(body)), true
+	}
+	return data, false
+}
+
+// GlobRewriter is a Transform that rescopes rule globs to the project's
+// layout at install time. Overrides in .claude/rule-globs.json win over the
+// per-stack mapping; rules without a mapping or override keep their globs.
+type GlobRewriter struct {
+	ProjectRoot string
+
+	once      sync.Once
+	techs     []stack.Tech
+	overrides map[string][]string
+	err       error
+}
+
+// Transform implements Transform for the rules kind.
+func (g *GlobRewriter) Transform(f *InstallFile) error {
+	if f.Kind != "rules" || f.RelPath != filepath.Base(f.Component)+".md" {
+		return nil
+	}
+
+	g.once.Do(func() {
+		g.techs = stack.DetectStack(g.ProjectRoot)
+		g.overrides, g.err = LoadGlobOverrides(filepath.Join(g.ProjectRoot, ".claude"))
+	})
+	if g.err != nil {
+		return g.err
+	}
+
+	globs, ok := g.overrides[f.Component]
+	if !ok {
+		globs = stack.RuleGlobs(g.ProjectRoot, f.Component, g.techs)
+	}
+	if len(globs) == 0 {
+		return nil
+	}
+
+	for _, key := range globKeys {
+		if out, ok := SetFrontmatterList(f.Data, key, globs); ok {
+			f.Data = out
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package catalog
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kind describes one type of component (agents, skills, ...) and knows how
+// to find, install and remove components of that type.
+type Kind interface {
+	// Name is the plural directory name, e.g. "skills".
+	Name() string
+	// Singular is the CLI alias, e.g. "skill".
+	Singular() string
+	// Describe returns a short human-readable description for help text.
+	Describe() string
+	// Scan returns the components found in dir (the kind's own directory).
+	Scan(dir string) []Component
+	// Path returns where component name lives under baseDir.
+	Path(baseDir, name string) string
+	// Copy installs component name from templateDir into targetDir.
+	Copy(templateDir, targetDir, name string) error
+	// Remove deletes component name from targetDir.
+	Remove(targetDir, name string) error
+	// IsInstalled reports whether component name exists in targetDir.
+	IsInstalled(targetDir, name string) bool
+}
+
+var kinds []Kind
+
+// RegisterKind adds a component kind to the registry. Kinds are listed in
+// registration order. Registering a name twice panics.
+func RegisterKind(k Kind) {
+	for _, existing := range kinds {
+		if existing.Name() == k.Name() {
+			panic(fmt.Sprintf("catalog: kind %q registered twice", k.Name()))
+		}
+	}
+	kinds = append(kinds, k)
+}
+
+// Kinds returns all registered kinds in registration order.
+func Kinds() []Kind {
+	return kinds
+}
+
+// KindNames returns the plural names of all registered kinds.
+func KindNames() []string {
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = k.Name()
+	}
+	return names
+}
+
+// LookupKind finds a kind by its plural or singular name (case-insensitive).
+func LookupKind(name string) (Kind, bool) {
+	name = strings.ToLower(name)
+	for _, k := range kinds {
+		if k.Name() == name || k.Singular() == name {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func init() {
+	RegisterKind(markdownKind{name: "agents", singular: "agent", desc: "role definitions that pull in their skills, rules and commands"})
+	RegisterKind(skillKind{})
+	RegisterKind(markdownKind{name: "commands", singular: "command", desc: "slash commands"})
+	RegisterKind(markdownKind{name: "rules", singular: "rule", desc: "project rules loaded by file pattern"})
+}
+
+// markdownKind is a kind whose components are single <name>.md files.
+type markdownKind struct {
+	name     string
+	singular string
+	desc     string
+}
+
+func (k markdownKind) Name() string     { return k.name }
+func (k markdownKind) Singular() string { return k.singular }
+func (k markdownKind) Describe() string { return k.desc }
+
+func (k markdownKind) Scan(dir string) []Component {
+	return scanMarkdownDir(dir, k.name)
+}
+
+func (k markdownKind) Path(baseDir, name string) string {
+	return filepath.Join(baseDir, k.name, name+".md")
+}
+
+func (k markdownKind) Copy(templateDir, targetDir, name string) error {
+	srcFile := k.Path(templateDir, name)
+	if _, err := os.Stat(srcFile); err != nil {
+		return fmt.Errorf("%s not found: %s: %w", k.name, name, fs.ErrNotExist)
+	}
+
+	dstFile := k.Path(targetDir, name)
+	if err := os.MkdirAll(filepath.Dir(dstFile), 0o755); err != nil {
+		return err
+	}
+
+	f := InstallFile{
+		Kind:      k.name,
+		Component: name,
+		RelPath:   filepath.Base(srcFile),
+		Templated: IsTemplated(srcFile),
+	}
+	return installFile(f, srcFile, dstFile)
+}
+
+func (k markdownKind) Remove(targetDir, name string) error {
+	return os.Remove(k.Path(targetDir, name))
+}
+
+func (k markdownKind) IsInstalled(targetDir, name string) bool {
+	_, err := os.Stat(k.Path(targetDir, name))
+	return err == nil
+}
+
+// skillKind is a kind whose components are directories containing SKILL.md.
+type skillKind struct{}
+
+func (skillKind) Name() string     { return "skills" }
+func (skillKind) Singular() string { return "skill" }
+func (skillKind) Describe() string { return "skill directories (SKILL.md + supporting files)" }
+
+func (k skillKind) Scan(dir string) []Component {
+	return scanSkills(dir, k.Name())
+}
+
+func (skillKind) Path(baseDir, name string) string {
+	return filepath.Join(baseDir, "skills", name)
+}
+
+func (k skillKind) Copy(templateDir, targetDir, name string) error {
+	srcDir := k.Path(templateDir, name)
+	if _, err := os.Stat(srcDir); err != nil {
+		return fmt.Errorf("skill not found: %s: %w", name, fs.ErrNotExist)
+	}
+
+	dstDir := k.Path(targetDir, name)
+	if err := os.MkdirAll(filepath.Dir(dstDir), 0o755); err != nil {
+		return err
+	}
+
+	f := InstallFile{
+		Kind:      k.Name(),
+		Component: name,
+		Templated: IsTemplated(filepath.Join(srcDir, "SKILL.md")),
+	}
+	return installDir(f, srcDir, dstDir)
+}
+
+func (k skillKind) Remove(targetDir, name string) error {
+	return os.RemoveAll(k.Path(targetDir, name))
+}
+
+func (k skillKind) IsInstalled(targetDir, name string) bool {
+	_, err := os.Stat(filepath.Join(k.Path(targetDir, name), "SKILL.md"))
+	return err == nil
+}
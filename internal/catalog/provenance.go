@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Provenance records where an externally installed component came from.
+type Provenance struct {
+	Source      string    `json:"source"`            // catalog it was recommended from
+	URL         string    `json:"url"`               // URL it was installed from
+	Repo        string    `json:"repo,omitempty"`    // git repository
+	Archive     string    `json:"archive,omitempty"` // tarball or zip, instead of a repository
+	Ref         string    `json:"ref,omitempty"`     // branch or tag requested
+	Commit      string    `json:"commit,omitempty"`  // resolved commit SHA
+	Path        string    `json:"path,omitempty"`    // path inside the repository
+	SHA256      string    `json:"sha256,omitempty"`  // content hash of single-file components
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// ProvenancePath returns where the provenance of component name is kept:
+// a hidden .<name>.provenance.json beside the component, outside it so
+// it never mixes with the component's own files.
+func ProvenancePath(k Kind, baseDir, name string) string {
+	p := k.Path(baseDir, name)
+	base := strings.TrimSuffix(filepath.Base(p), ".md")
+	return filepath.Join(filepath.Dir(p), "."+base+".provenance.json")
+}
+
+// WriteProvenance records p for an installed component.
+func WriteProvenance(targetDir, compType, name string, p Provenance) error {
+	k, ok := LookupKind(compType)
+	if !ok {
+		return fmt.Errorf("unknown component type: %s", compType)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ProvenancePath(k, targetDir, name), append(data, '\n'), 0o644)
+}
+
+// ReadProvenance returns the provenance of an installed component, or an
+// error satisfying errors.Is(err, fs.ErrNotExist) if it has none.
+func ReadProvenance(targetDir, compType, name string) (*Provenance, error) {
+	k, ok := LookupKind(compType)
+	if !ok {
+		return nil, fmt.Errorf("unknown component type: %s", compType)
+	}
+	data, err := os.ReadFile(ProvenancePath(k, targetDir, name))
+	if err != nil {
+		return nil, err
+	}
+	var p Provenance
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("provenance of %s/%s: %w", compType, name, err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,86 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InstallFile is a component file on its way from the template into the
+// project. Transforms may rewrite Data before it is written.
+type InstallFile struct {
+	Kind      string // registered kind name, e.g. "rules"
+	Component string // component name, e.g. "security/auth-review"
+	RelPath   string // path relative to the component root, e.g. "SKILL.md"
+	Templated bool   // the component set `templated: true` in its frontmatter
+	Data      []byte
+}
+
+// Transform rewrites a file during CopyComponent.
+type Transform func(f *InstallFile) error
+
+var transforms []Transform
+
+// AddTransform registers a transform applied, in registration order, to
+// every file installed by CopyComponent.
+func AddTransform(t Transform) {
+	transforms = append(transforms, t)
+}
+
+// IsTemplated reports whether a component's main file opts into rendering.
+func IsTemplated(mainFile string) bool {
+	return strings.EqualFold(ExtractField(mainFile, "templated"), "true")
+}
+
+// installFile reads src, runs the registered transforms and writes dst.
+func installFile(f InstallFile, src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	f.Data = data
+
+	for _, t := range transforms {
+		if err := t(&f); err != nil {
+			return err
+		}
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, f.Data, info.Mode().Perm()|0o600)
+}
+
+// installDir recursively installs a component directory through installFile.
+func installDir(f InstallFile, src, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+
+		sub := f
+		sub.RelPath = filepath.ToSlash(filepath.Join(f.RelPath, entry.Name()))
+
+		if entry.IsDir() {
+			if err := installDir(sub, srcPath, dstPath); err != nil {
+				return err
+			}
+		} else {
+			if err := installFile(sub, srcPath, dstPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
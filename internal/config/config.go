@@ -4,12 +4,16 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 )
 
 const (
 	DefaultTemplateDirName = "templates"
 	BmadDirName            = ".bmad"
 	ClaudeDirName          = ".claude"
+	SourcesDirName         = "sources"
+	CacheDirName           = "cache"
 )
 
 // TemplateDir resolves the template directory using this priority:
@@ -59,6 +63,64 @@ func InstalledTemplatesDir() string {
 	return filepath.Join(home, BmadDirName, DefaultTemplateDirName)
 }
 
+// QuarantineDir returns ~/.bmad/quarantine/, where external components are
+// staged for review before installation.
+func QuarantineDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, BmadDirName, "quarantine")
+}
+
+// CatalogCacheDir returns ~/.bmad/cache/catalogs/.
+func CatalogCacheDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, BmadDirName, CacheDirName, "catalogs")
+}
+
+// Source is a named template directory components can be found in.
+type Source struct {
+	Name string
+	Dir  string
+}
+
+// LocalSourceName names the main template directory (see TemplateDir).
+const LocalSourceName = "local"
+
+// ExtraTemplateSources returns template directories besides the main one:
+// every directory under ~/.bmad/sources/ (named after it) and the name=dir
+// pairs in $BMAD_TEMPLATE_SOURCES, separated by the OS path list separator
+// (e.g. "team=/srv/team-templates:ml=/opt/ml-kit"). The environment wins
+// on name clashes. Sources are sorted by name.
+func ExtraTemplateSources() []Source {
+	byName := make(map[string]string)
+
+	if home, err := os.UserHomeDir(); err == nil {
+		dir := filepath.Join(home, BmadDirName, SourcesDirName)
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, e := range entries {
+				if e.IsDir() {
+					byName[e.Name()] = filepath.Join(dir, e.Name())
+				}
+			}
+		}
+	}
+
+	for _, entry := range filepath.SplitList(os.Getenv("BMAD_TEMPLATE_SOURCES")) {
+		name, dir, ok := strings.Cut(entry, "=")
+		if ok && name != "" && dir != "" {
+			byName[name] = dir
+		}
+	}
+
+	sources := make([]Source, 0, len(byName))
+	for name, dir := range byName {
+		if name != LocalSourceName {
+			sources = append(sources, Source{Name: name, Dir: dir})
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources
+}
+
 // IsWindows returns true if running on Windows.
 func IsWindows() bool {
 	return runtime.GOOS == "windows"
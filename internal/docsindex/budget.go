@@ -0,0 +1,97 @@
+package docsindex
+
+import (
+	"fmt"
+	"sort"
+)
+
+// categoryWeight ranks directives when the index must be trimmed: framework
+// notes are the most specific and are kept longest, tools go first.
+var categoryWeight = map[string]int{
+	"framework": 40,
+	"language":  30,
+	"runtime":   20,
+	"database":  20,
+	"cloud":     20,
+	"messaging": 20,
+	"test":      10,
+	"lint":      10,
+	"ci":        10,
+	"tool":      10,
+}
+
+// overrideWeight keeps directives the project explicitly overrode.
+const overrideWeight = 100
+
+// EstimateTokens approximates the token count of markdown text at roughly
+// four characters per token, rounding up.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// unit is one directive as it will be written to the index.
+type unit struct {
+	Package  string // package path in monorepos, "" otherwise
+	Tech     Tech
+	Body     string
+	Priority int
+}
+
+// label names a unit in the trimmed report.
+func (u unit) label() string {
+	if u.Package == "" || u.Package == "." {
+		return u.Tech.Name
+	}
+	return fmt.Sprintf("%s (%s)", u.Tech.Name, u.Package)
+}
+
+// unitPriority scores a directive by category, by how many packages use the
+// technology and by whether the project overrode it.
+func unitPriority(t Tech, packageCount int, overrides map[string]override) int {
+	p := categoryWeight[t.Category] + packageCount
+	if o, ok := overrides[t.Name]; ok && o.Mode != ModeSuppress {
+		p += overrideWeight
+	}
+	return p
+}
+
+// fitBudget drops the lowest-priority units until render's output fits in
+// maxTokens (0 means unlimited). Ties are broken by dropping later units
+// first. It returns the rendered content, its size and the trimmed units.
+func fitBudget(units []unit, maxTokens int, render func(kept, trimmed []unit) string) (string, int, []unit) {
+	content := render(units, nil)
+	if maxTokens <= 0 || EstimateTokens(content) <= maxTokens {
+		return content, EstimateTokens(content), nil
+	}
+
+	// Drop order: lowest priority first, later entries before earlier ones
+	order := make([]int, len(units))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if units[order[a]].Priority != units[order[b]].Priority {
+			return units[order[a]].Priority < units[order[b]].Priority
+		}
+		return order[a] > order[b]
+	})
+
+	dropped := make(map[int]bool)
+	var trimmed []unit
+	for _, idx := range order {
+		dropped[idx] = true
+		trimmed = append(trimmed, units[idx])
+
+		var kept []unit
+		for i, u := range units {
+			if !dropped[i] {
+				kept = append(kept, u)
+			}
+		}
+		content = render(kept, trimmed)
+		if EstimateTokens(content) <= maxTokens {
+			break
+		}
+	}
+	return content, EstimateTokens(content), trimmed
+}
@@ -0,0 +1,105 @@
+package docsindex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnitPriority(t *testing.T) {
+	overrides := map[string]override{
+		"eslint": {Mode: ModeAppend},
+		"vue":    {Mode: ModeSuppress},
+	}
+	tests := []struct {
+		tech     Tech
+		packages int
+		want     int
+	}{
+		{Tech{Name: "nextjs", Category: "framework"}, 1, 41},
+		{Tech{Name: "go", Category: "language"}, 3, 33},
+		{Tech{Name: "docker", Category: "tool"}, 1, 11},
+		{Tech{Name: "eslint", Category: "lint"}, 1, 111},  // overridden
+		{Tech{Name: "vue", Category: "framework"}, 1, 41}, // suppressed overrides do not count
+		{Tech{Name: "custom", Category: "unknown"}, 2, 2},
+	}
+	for _, tt := range tests {
+		if got := unitPriority(tt.tech, tt.packages, overrides); got != tt.want {
+			t.Errorf("unitPriority(%s) = %d, want %d", tt.tech.Name, got, tt.want)
+		}
+	}
+}
+
+func TestFitBudget(t *testing.T) {
+	body := strings.Repeat("x", 40) // 10 tokens
+	units := []unit{
+		{Tech: Tech{Name: "nextjs"}, Body: body, Priority: 41},
+		{Tech: Tech{Name: "docker"}, Body: body, Priority: 11},
+		{Tech: Tech{Name: "typescript"}, Body: body, Priority: 31},
+		{Tech: Tech{Name: "eslint"}, Body: body, Priority: 11},
+		{Tech: Tech{Name: "postgres"}, Body: body, Priority: 21},
+	}
+	render := func(kept, _ []unit) string {
+		var sb strings.Builder
+		for _, u := range kept {
+			sb.WriteString(u.Body)
+		}
+		return sb.String()
+	}
+	names := func(us []unit) []string {
+		var out []string
+		for _, u := range us {
+			out = append(out, u.Tech.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		maxTokens   int
+		wantTokens  int
+		wantTrimmed []string
+	}{
+		{0, 50, nil},
+		{50, 50, nil},
+		// Ties drop the later unit first: eslint before docker
+		{40, 40, []string{"eslint"}},
+		{30, 30, []string{"eslint", "docker"}},
+		{20, 20, []string{"eslint", "docker", "postgres"}},
+		{10, 10, []string{"eslint", "docker", "postgres", "typescript"}},
+		// Everything goes when even the framework does not fit
+		{5, 0, []string{"eslint", "docker", "postgres", "typescript", "nextjs"}},
+	}
+	for _, tt := range tests {
+		_, tokens, trimmed := fitBudget(units, tt.maxTokens, render)
+		if tokens != tt.wantTokens || !reflect.DeepEqual(names(trimmed), tt.wantTrimmed) {
+			t.Errorf("fitBudget(%d) = %d tokens, trimmed %v; want %d, %v", tt.maxTokens, tokens, names(trimmed), tt.wantTokens, tt.wantTrimmed)
+		}
+	}
+}
+
+func TestGenerateBudget(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"package.json":  `{"dependencies": {"next": "14.1.0"}, "devDependencies": {"typescript": "5.3.3", "eslint": "8.56.0"}}`,
+		"tsconfig.json": "{}",
+	})
+	full, err := Generate(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Generate(root, Options{MaxTokens: full.EstimatedTokens - 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Trimmed) == 0 || res.EstimatedTokens > res.MaxTokens {
+		t.Fatalf("result = %+v, want trimmed directives within the budget", res)
+	}
+	// Tools and lint go before the framework
+	if res.Trimmed[0] == "nextjs" {
+		t.Errorf("trimmed %v; the framework directive went first", res.Trimmed)
+	}
+	index := readIndex(t, root)
+	if !strings.Contains(index, "## Next.js") || !strings.Contains(index, "_Trimmed to fit the") {
+		t.Errorf("docs-index:\n%s", index)
+	}
+}
@@ -1,10 +1,13 @@
 package docsindex
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,14 +22,40 @@ const (
 
 // Meta stores metadata about the last docs-index generation.
 type Meta struct {
-	GeneratedAt    string   `json:"generated_at"`
-	DependencyHash string   `json:"dependency_hash"`
-	DetectedStack  []string `json:"detected_stack"`
+	GeneratedAt      string              `json:"generated_at"`
+	DependencyHash   string              `json:"dependency_hash"`
+	DetectedStack    []string            `json:"detected_stack"`
+	Versions         map[string]string   `json:"versions,omitempty"`  // tech → resolved version
+	Overrides        map[string]string   `json:"overrides,omitempty"` // tech → override mode from docs-overrides/
+	OverridesHash    string              `json:"overrides_hash,omitempty"`
+	EstimatedTokens  int                 `json:"estimated_tokens"`
+	MaxTokens        int                 `json:"max_tokens,omitempty"`
+	Trimmed          []string            `json:"trimmed,omitempty"`
+	GeneratorVersion string              `json:"generator_version,omitempty"` // ck version that wrote the index
+	DefinitionsHash  string              `json:"definitions_hash,omitempty"`  // template stacks/*.yaml
+	MaxAgeDays       int                 `json:"max_age_days,omitempty"`
+	Packages         map[string][]string `json:"packages,omitempty"` // monorepo package path → stack
 }
 
-// frameworkDirectives contains compressed stack-specific notes for each technology.
-var frameworkDirectives = map[string]string{
-	"nextjs": `## Next.js
+// directive is a compressed note for a technology, optionally limited to a
+// version range (see stack.MatchVersion).
+type directive struct {
+	Versions string // e.g. "<13"; empty applies to any version
+	Body     string
+}
+
+// frameworkDirectives contains compressed stack-specific notes for each
+// technology. Entries are ordered oldest to newest; when the version is
+// unknown the last entry (the current major) is used.
+var frameworkDirectives = map[string][]directive{
+	"nextjs": {{Versions: "<13", Body: `## Next.js (Pages Router)
+- Pages Router: pages/ dir, _app.tsx for layout, _document.tsx for HTML shell
+- Data fetching: getServerSideProps, getStaticProps, getStaticPaths
+- API routes: pages/api/*.ts exporting a default handler
+- Use next/image for images, next/link for navigation
+- Head: next/head for per-page metadata
+- Middleware (12.2+): middleware.ts at project root for auth, redirects
+- Environment: NEXT_PUBLIC_ prefix for client-side env vars only`}, {Versions: ">=13", Body: `## Next.js
 - App Router: use app/ dir, layouts, loading.tsx, error.tsx, not-found.tsx
 - Server Components by default; add "use client" only for interactivity
 - Use next/image for images, next/link for navigation, next/font for fonts
@@ -34,176 +63,445 @@ var frameworkDirectives = map[string]string{
 - Route handlers: app/api/route.ts (GET, POST exports), not pages/api
 - Metadata: export metadata object or generateMetadata() for SEO
 - Middleware: middleware.ts at project root for auth, redirects
-- Environment: NEXT_PUBLIC_ prefix for client-side env vars only`,
+- Environment: NEXT_PUBLIC_ prefix for client-side env vars only`}},
 
-	"react": `## React
+	"react": {{Body: `## React
 - Functional components + hooks, never class components
 - useState for local state, useReducer for complex state
 - useEffect: always specify deps array, clean up subscriptions
 - useMemo/useCallback only for measurable performance issues, not by default
 - Custom hooks for shared stateful logic, prefix with "use"
 - Lifting state: move state to lowest common ancestor
-- Controlled components for forms; ref only for focus/scroll/measure`,
-
-	"vue": `## Vue
+- Controlled components for forms; ref only for focus/scroll/measure`}},
+
+	"vue": {{Versions: "<3", Body: `## Vue 2
+- Options API (data, computed, methods, watch); Composition API via @vue/composition-api only if already used
+- Vuex for global state, mixins sparingly for shared logic
+- props with type/required/default definitions
+- v-model on components: value prop + input event
+- Vue 2 is end-of-life: flag upgrade blockers, avoid new Vue 2-only plugins`}, {Versions: ">=3", Body: `## Vue
 - Composition API with <script setup>, not Options API for new code
 - ref() for primitives, reactive() for objects
 - computed() for derived state, watch() sparingly
 - defineProps/defineEmits with TypeScript generics for type safety
 - Pinia for global state, composables for shared logic
-- Teleport for modals/tooltips, Suspense for async components`,
+- Teleport for modals/tooltips, Suspense for async components`}},
 
-	"angular": `## Angular
+	"angular": {{Body: `## Angular
 - Standalone components, not NgModules for new code
 - Signals for reactive state, not zone.js patterns
 - inject() function over constructor injection
 - OnPush change detection on all components
 - RxJS: use async pipe in templates, avoid manual subscribe
-- Strong typing: no "any", use strict template checking`,
+- Strong typing: no "any", use strict template checking`}},
 
-	"svelte": `## Svelte
-- Runes ($state, $derived, $effect) for reactivity in Svelte 5+
+	"svelte": {{Versions: "<5", Body: `## Svelte
+- Reactivity via assignment; $: for reactive statements and derived values
+- export let for component props
+- Slots (named and default) for content projection
+- Stores (writable, readable, derived) for shared state; $store auto-subscribe
+- Use SvelteKit for full apps: +page.svelte, +layout.svelte, +server.ts
+- Form actions for mutations, load functions for data fetching`}, {Versions: ">=5", Body: `## Svelte
+- Runes ($state, $derived, $effect) for reactivity
 - $props() for component props, not export let
 - Snippets over slots for content projection
 - Use SvelteKit for full apps: +page.svelte, +layout.svelte, +server.ts
-- Form actions for mutations, load functions for data fetching`,
+- Form actions for mutations, load functions for data fetching`}},
 
-	"express": `## Express
+	"express": {{Body: `## Express
 - Router-level middleware for route groups, app-level for global
 - Error middleware: 4 args (err, req, res, next), register last
 - Validate request body/params/query at handler entry
 - Use helmet for security headers, cors for CORS
 - Async handlers: wrap with try/catch or express-async-errors
-- Never send stack traces in production error responses`,
+- Never send stack traces in production error responses`}},
 
-	"fastify": `## Fastify
+	"fastify": {{Body: `## Fastify
 - Schema-based validation with JSON Schema or TypeBox
 - Plugins for encapsulation: register() with prefix
 - Decorators for shared utilities, not global state
 - Use @fastify/autoload for route auto-discovery
-- Serialization schemas for response type safety and speed`,
+- Serialization schemas for response type safety and speed`}},
 
-	"nestjs": `## NestJS
+	"nestjs": {{Body: `## NestJS
 - Modules for feature boundaries, providers for services
 - DTOs with class-validator for request validation
 - Guards for auth, Interceptors for transform/logging, Pipes for validation
 - Repository pattern for data access, never query in controllers
-- ConfigModule with validation for environment variables`,
+- ConfigModule with validation for environment variables`}},
 
-	"django": `## Django
+	"django": {{Body: `## Django
 - Class-based views for CRUD, function views for custom logic
 - Models: use migrations, never modify DB directly
 - Forms/serializers for validation, never trust request.data raw
 - Templates: use template tags, avoid logic in templates
 - Settings: split base/dev/prod, use django-environ for env vars
-- ORM: select_related/prefetch_related to avoid N+1 queries`,
+- ORM: select_related/prefetch_related to avoid N+1 queries`}},
 
-	"flask": `## Flask
+	"flask": {{Body: `## Flask
 - Application factory pattern with create_app()
 - Blueprints for feature modules, not everything in app.py
 - Flask-SQLAlchemy for ORM, Flask-Migrate for migrations
 - Validate with marshmallow or pydantic, not manual checks
-- Error handlers: @app.errorhandler for consistent error format`,
+- Error handlers: @app.errorhandler for consistent error format`}},
 
-	"fastapi": `## FastAPI
+	"fastapi": {{Body: `## FastAPI
 - Pydantic models for request/response validation
 - Dependency injection for auth, DB sessions, config
 - Background tasks for non-blocking operations
 - Router prefixes for API versioning
 - async def for I/O-bound handlers, def for CPU-bound
-- Settings with pydantic-settings for typed env config`,
+- Settings with pydantic-settings for typed env config`}},
 
-	"rails": `## Rails
+	"rails": {{Body: `## Rails
 - Convention over configuration: follow Rails naming/structure
 - Strong Parameters: require/permit in controllers
 - ActiveRecord: scopes, validations, callbacks sparingly
 - Service objects for complex business logic
 - Concerns for shared model/controller behavior
-- N+1: use includes/preload/eager_load`,
+- N+1: use includes/preload/eager_load`}},
 
-	"typescript": `## TypeScript
+	"typescript": {{Body: `## TypeScript
 - Strict mode always: strict: true in tsconfig.json
 - Prefer type over interface for unions and intersections
 - Use satisfies for type-safe object literals
 - Discriminated unions over type assertions
 - Branded types for domain IDs (UserId, OrderId)
 - Template literal types for string patterns
-- No any — use unknown for truly unknown types, then narrow`,
+- No any — use unknown for truly unknown types, then narrow`}},
 
-	"go": `## Go
+	"go": {{Body: `## Go
 - Accept interfaces, return structs
 - Error wrapping: fmt.Errorf("context: %w", err)
 - Table-driven tests, testify for assertions
 - Context propagation: first param, never store in struct
 - Goroutines: always handle cleanup (defer, context cancellation)
 - Struct embedding for composition, not inheritance
-- io.Reader/io.Writer for streaming, not []byte`,
+- io.Reader/io.Writer for streaming, not []byte`}},
 
-	"python": `## Python
+	"python": {{Body: `## Python
 - Type hints on all function signatures, use mypy/pyright
 - Dataclasses or Pydantic for data structures, not raw dicts
 - Virtual env per project (venv, poetry, uv)
 - f-strings for formatting, pathlib for file paths
 - Context managers for resource cleanup
-- List/dict comprehensions over map/filter where clearer`,
+- List/dict comprehensions over map/filter where clearer`}},
 
-	"terraform": `## Terraform
+	"terraform": {{Body: `## Terraform
 - Modules for reusable infrastructure components
 - Remote state with locking (S3+DynamoDB, GCS, etc.)
 - Variables with descriptions and validation blocks
 - Outputs for cross-module references
 - lifecycle { prevent_destroy } on stateful resources
-- Use moved blocks for refactoring, not manual state surgery`,
+- Use moved blocks for refactoring, not manual state surgery`}},
 
-	"docker": `## Docker
+	"docker": {{Body: `## Docker
 - Multi-stage builds to minimize image size
 - Run as non-root user (USER directive)
 - COPY specific files, not . (use .dockerignore)
 - Pin base image versions, not :latest
 - One process per container
-- HEALTHCHECK for orchestrator integration`,
+- HEALTHCHECK for orchestrator integration`}},
 
-	"kubernetes": `## Kubernetes
+	"kubernetes": {{Body: `## Kubernetes
 - Resource requests AND limits on all containers
 - Liveness + readiness probes, distinct endpoints
 - ConfigMap/Secret for config, not baked into images
 - NetworkPolicy to restrict pod-to-pod traffic
 - PodDisruptionBudget for availability during updates
-- Labels: app, version, component, managed-by`,
+- Labels: app, version, component, managed-by`}},
 
-	"prisma": `## Prisma
+	"prisma": {{Body: `## Prisma
 - Schema-first: define models in schema.prisma
 - Migrations: npx prisma migrate dev, never push to prod
 - Use select/include to fetch only needed fields
 - Transactions: prisma.$transaction for multi-step operations
-- Middleware for logging, soft deletes`,
+- Middleware for logging, soft deletes`}},
 
-	"tailwind": `## Tailwind CSS
+	"tailwind": {{Body: `## Tailwind CSS
 - Use @apply sparingly — prefer utility classes in markup
 - Design tokens via theme.extend in tailwind.config
 - Responsive: mobile-first (sm:, md:, lg: breakpoints)
 - Dark mode: class strategy for manual toggle support
-- Purge unused styles: content paths in config`,
+- Purge unused styles: content paths in config`}},
 
-	"github-actions": `## GitHub Actions
+	"github-actions": {{Body: `## GitHub Actions
 - Pin action versions to SHA, not @main or @v1
 - Use GITHUB_TOKEN, not PATs, where possible
 - Cache dependencies (actions/cache) for faster runs
 - Matrix strategy for cross-platform/version testing
-- Concurrency groups to cancel redundant runs`,
+- Concurrency groups to cancel redundant runs`}},
+
+	"gitlab-ci": {{Body: `## GitLab CI
+- Pin images by digest or exact tag; avoid :latest
+- rules: instead of only/except; needs: for DAG pipelines
+- Cache keyed on lockfiles; artifacts with expire_in
+- Protected and masked variables for secrets, never in .gitlab-ci.yml
+- include:/extends: for shared job templates instead of copy-paste`}},
+
+	"jest": {{Body: `## Jest
+- Colocate tests as *.test.ts; describe per unit, it per behavior
+- Mock at module boundaries (jest.mock), reset with restoreMocks in config
+- Prefer findBy*/waitFor over timers for async UI tests
+- Avoid snapshot tests for logic; keep snapshots small and reviewed`}},
+
+	"vitest": {{Body: `## Vitest
+- Run once in CI with vitest run (watch mode is the default)
+- vi.mock is hoisted; use vi.hoisted for values the factory needs
+- Reset mocks via restoreMocks/mockReset in config, not per test
+- Use the same Vite config/aliases as the app; environment per file via // @vitest-environment`}},
+
+	"pytest": {{Body: `## pytest
+- Plain assert statements; fixtures over setUp/tearDown
+- Share fixtures via conftest.py; scope expensive ones (session/module)
+- @pytest.mark.parametrize instead of loops in tests
+- tmp_path and monkeypatch instead of touching real files or env`}},
+
+	"rspec": {{Body: `## RSpec
+- describe for the unit, context for state ("when …"), it for one behavior
+- let/let! over instance variables; FactoryBot over fixtures
+- Request specs over controller specs; avoid any_instance_of
+- Keep specs independent of order (random order enabled)`}},
+
+	"postgres": {{Body: `## PostgreSQL
+- Schema changes through migrations only, reviewed like code
+- Add indexes CONCURRENTLY; add NOT NULL columns in steps (nullable, backfill, constrain)
+- Parameterized queries only, never string-built SQL
+- EXPLAIN (ANALYZE, BUFFERS) before merging queries on large tables
+- Connection pooling (pgbouncer or driver pool); keep transactions short
+- timestamptz for times, text over varchar(n), identity over serial`}},
+
+	"mysql": {{Body: `## MySQL
+- Schema changes through migrations; use online DDL (ALGORITHM=INPLACE/INSTANT) on large tables
+- utf8mb4 charset and utf8mb4_0900_ai_ci collation, never utf8
+- Parameterized queries only, never string-built SQL
+- InnoDB only; explicit primary keys on every table
+- EXPLAIN queries that touch large tables; avoid SELECT *`}},
+
+	"redis": {{Body: `## Redis
+- Set a TTL on every cache key; namespace keys (app:entity:id)
+- Never use KEYS in application code; use SCAN
+- Treat Redis as a cache unless persistence is configured deliberately
+- Pipeline or MULTI for batches; avoid large values and hot keys
+- Handle connection loss: the app must degrade, not crash`}},
+
+	"mongodb": {{Body: `## MongoDB
+- Define indexes in code/migrations; every query pattern needs one
+- Schema validation ($jsonSchema) or ODM schemas for collections
+- Avoid unbounded arrays in documents; paginate with range queries, not skip
+- Use transactions only where multi-document atomicity is required
+- Never build queries from raw user objects (operator injection)`}},
+
+	"kafka": {{Body: `## Kafka
+- Consumers must be idempotent; assume at-least-once delivery
+- Key messages by entity ID to preserve per-entity ordering
+- Schemas (Avro/Protobuf/JSON Schema) with compatible evolution only
+- Commit offsets after processing, not before
+- Dead-letter topic for poison messages; never block a partition`}},
+
+	"rabbitmq": {{Body: `## RabbitMQ
+- Durable queues and persistent messages for anything that matters
+- Manual acks after processing; set prefetch (basic.qos)
+- Dead-letter exchanges for rejected or expired messages
+- Consumers must be idempotent; redelivery happens`}},
+
+	"aws": {{Body: `## AWS
+- Least-privilege IAM: scoped policies per service, no wildcards on actions/resources
+- Credentials from roles (IRSA, instance profiles, SSO), never access keys in code
+- Tag every resource (owner, environment, cost-center) for cost allocation
+- Encrypt at rest (KMS) and in transit; block public S3 access
+- Consider cost impact of new resources (NAT gateways, data transfer, idle capacity)`}},
+
+	"gcp": {{Body: `## Google Cloud
+- Dedicated service accounts per workload with least-privilege roles
+- Workload Identity instead of service account key files
+- Labels on every resource (owner, environment, cost-center)
+- Secrets in Secret Manager, not env files or images
+- Consider cost impact of new resources (egress, idle instances, log volume)`}},
+
+	"azure": {{Body: `## Azure
+- Managed identities instead of connection strings or client secrets
+- RBAC scoped to resource groups or resources, not subscriptions
+- Tags on every resource (owner, environment, cost-center)
+- Secrets in Key Vault; private endpoints for data services
+- Consider cost impact of new resources (SKU tier, egress, idle capacity)`}},
+}
+
+// Options controls docs-index generation.
+type Options struct {
+	// MaxTokens is the estimated token budget for docs-index.md; directives
+	// are trimmed by priority to fit. 0 means unlimited.
+	MaxTokens int
+
+	// Version is the ck version generating the index. An index written by
+	// another version is stale, since built-in directives may differ.
+	Version string
+
+	// MaxAgeDays is the age after which the index is stale; 0 uses StaleDays.
+	MaxAgeDays int
+
+	// TemplateDir is the template directory whose stack definitions supply
+	// directives. Its stacks/ files are hashed so that template updates
+	// make the index stale.
+	TemplateDir string
+}
+
+// Result describes a generated docs-index.
+type Result struct {
+	Stack           []string // detected technology names
+	EstimatedTokens int
+	MaxTokens       int
+	Trimmed         []string // directives dropped to fit the budget
 }
 
 // Generate creates docs-index.md and .docs-meta.json in the project root.
-func Generate(projectRoot string) ([]string, error) {
-	techs := stack.DetectStack(projectRoot)
+func Generate(projectRoot string, opts Options) (*Result, error) {
+	packages := stack.DetectPackages(projectRoot)
+	techs := stack.Merge(packages)
 	depHash := stack.ComputeDependencyHash(projectRoot)
+	overrides, err := loadOverrides(projectRoot)
+	if err != nil {
+		return nil, err
+	}
 
 	var techNames []string
 	for _, t := range techs {
 		techNames = append(techNames, t.Name)
 	}
 
-	// Build the docs-index.md content
+	units := collectUnits(techs, packages, overrides)
+	content, tokens, trimmedUnits := fitBudget(units, opts.MaxTokens, func(kept, trimmed []unit) string {
+		return renderIndex(techs, packages, kept, trimmed, opts.MaxTokens)
+	})
+
+	var trimmed []string
+	for _, u := range trimmedUnits {
+		trimmed = append(trimmed, u.label())
+	}
+
+	// Write docs-index.md
+	claudeDir := filepath.Join(projectRoot, ".claude")
+	if err := os.MkdirAll(claudeDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating .claude directory: %w", err)
+	}
+
+	indexPath := filepath.Join(claudeDir, DocsIndexFile)
+	if err := os.WriteFile(indexPath, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("writing docs-index.md: %w", err)
+	}
+
+	// Write .docs-meta.json
+	meta := Meta{
+		GeneratedAt:      time.Now().UTC().Format(time.RFC3339),
+		DependencyHash:   depHash,
+		DetectedStack:    techNames,
+		Overrides:        overrideModes(overrides),
+		OverridesHash:    ComputeOverridesHash(projectRoot),
+		EstimatedTokens:  tokens,
+		MaxTokens:        opts.MaxTokens,
+		Trimmed:          trimmed,
+		GeneratorVersion: opts.Version,
+		DefinitionsHash:  ComputeDefinitionsHash(opts.TemplateDir),
+		MaxAgeDays:       opts.MaxAgeDays,
+	}
+	for _, t := range techs {
+		if t.Version != "" {
+			if meta.Versions == nil {
+				meta.Versions = make(map[string]string)
+			}
+			meta.Versions[t.Name] = t.Version
+		}
+	}
+	if len(packages) > 1 {
+		meta.Packages = make(map[string][]string, len(packages))
+		for _, pkg := range packages {
+			meta.Packages[pkg.Path] = pkgTechNames(pkg)
+		}
+	}
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling meta: %w", err)
+	}
+
+	metaPath := filepath.Join(claudeDir, DocsMetaFile)
+	if err := os.WriteFile(metaPath, metaData, 0o644); err != nil {
+		return nil, fmt.Errorf("writing .docs-meta.json: %w", err)
+	}
+
+	return &Result{
+		Stack:           techNames,
+		EstimatedTokens: tokens,
+		MaxTokens:       opts.MaxTokens,
+		Trimmed:         trimmed,
+	}, nil
+}
+
+// SetMaxAge records the max age in .docs-meta.json without regenerating
+// the index.
+func SetMaxAge(projectRoot string, days int) error {
+	meta, err := LoadMeta(projectRoot)
+	if err != nil {
+		return err
+	}
+	meta.MaxAgeDays = days
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling meta: %w", err)
+	}
+	return os.WriteFile(filepath.Join(projectRoot, ".claude", DocsMetaFile), data, 0o644)
+}
+
+// LoadMeta reads .claude/.docs-meta.json.
+func LoadMeta(projectRoot string) (*Meta, error) {
+	data, err := os.ReadFile(filepath.Join(projectRoot, ".claude", DocsMetaFile))
+	if err != nil {
+		return nil, err
+	}
+	var meta Meta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", DocsMetaFile, err)
+	}
+	return &meta, nil
+}
+
+// collectUnits lists the directives to write, in output order. In
+// monorepos each directive belongs to the first package using it; later
+// packages refer back to it.
+func collectUnits(techs []Tech, packages []stack.Package, overrides map[string]override) []unit {
+	packageCount := make(map[string]int)
+	for _, pkg := range packages {
+		for _, t := range pkg.Techs {
+			packageCount[t.Name]++
+		}
+	}
+
+	var units []unit
+	if len(packages) <= 1 {
+		for _, t := range techs {
+			if directive, ok := resolveDirective(t, overrides); ok {
+				units = append(units, unit{Tech: t, Body: directive, Priority: unitPriority(t, packageCount[t.Name], overrides)})
+			}
+		}
+		return units
+	}
+
+	seen := make(map[string]bool)
+	for _, pkg := range packages {
+		for _, t := range pkg.Techs {
+			directive, ok := resolveDirective(t, overrides)
+			if !ok || seen[directive] {
+				continue
+			}
+			seen[directive] = true
+			units = append(units, unit{Package: pkg.Path, Tech: t, Body: directive, Priority: unitPriority(t, packageCount[t.Name], overrides)})
+		}
+	}
+	return units
+}
+
+// renderIndex builds docs-index.md from the kept directive units, noting
+// any that were trimmed to fit the token budget.
+func renderIndex(techs []Tech, packages []stack.Package, units, trimmed []unit, maxTokens int) string {
 	var sb strings.Builder
 	sb.WriteString("# Docs Index\n\n")
 	sb.WriteString("<!-- Auto-generated by bmad docs. Do not edit manually. -->\n")
@@ -211,87 +509,228 @@ func Generate(projectRoot string) ([]string, error) {
 
 	if len(techs) == 0 {
 		sb.WriteString("No stack detected. Add dependency files (package.json, go.mod, etc.) and re-run.\n")
+		return sb.String()
+	}
+
+	techNames := make([]string, len(techs))
+	for i, t := range techs {
+		techNames[i] = t.Name
+	}
+	sb.WriteString(fmt.Sprintf("**Detected stack:** %s\n\n", strings.Join(techNames, ", ")))
+
+	// Group by category
+	categories := map[string][]Tech{
+		"language":  {},
+		"framework": {},
+		"runtime":   {},
+		"database":  {},
+		"messaging": {},
+		"cloud":     {},
+		"test":      {},
+		"lint":      {},
+		"ci":        {},
+		"tool":      {},
+	}
+	for _, t := range techs {
+		categories[t.Category] = append(categories[t.Category], t)
+	}
+
+	// Summary table
+	sb.WriteString("| Category | Technologies |\n")
+	sb.WriteString("|----------|-------------|\n")
+	for _, cat := range []string{"language", "runtime", "framework", "database", "messaging", "cloud", "test", "lint", "ci", "tool"} {
+		if len(categories[cat]) > 0 {
+			names := make([]string, len(categories[cat]))
+			for i, t := range categories[cat] {
+				names[i] = techLabel(t)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", cat, strings.Join(names, ", ")))
+		}
+	}
+	sb.WriteString("\n")
+	if len(packages) > 0 && !packages[0].Commands.IsEmpty() {
+		sb.WriteString(fmt.Sprintf("**Commands:** %s\n\n", formatCommands(packages[0].Commands)))
+	}
+	sb.WriteString("---\n\n")
+
+	if len(packages) <= 1 {
+		// Framework-specific directives
+		for _, u := range units {
+			sb.WriteString(u.Body)
+			sb.WriteString("\n\n")
+		}
 	} else {
-		sb.WriteString(fmt.Sprintf("**Detected stack:** %s\n\n", strings.Join(techNames, ", ")))
-
-		// Group by category
-		categories := map[string][]Tech{
-			"language":  {},
-			"framework": {},
-			"runtime":   {},
-			"tool":      {},
+		writePackageSections(&sb, packages, units)
+	}
+
+	if len(trimmed) > 0 {
+		labels := make([]string, len(trimmed))
+		for i, u := range trimmed {
+			labels[i] = u.label()
 		}
-		for _, t := range techs {
-			categories[t.Category] = append(categories[t.Category], t)
+		sb.WriteString(fmt.Sprintf("_Trimmed to fit the %d-token budget: %s._\n", maxTokens, strings.Join(labels, ", ")))
+	}
+
+	return sb.String()
+}
+
+// writePackageSections emits a repository-wide section for the root followed
+// by one section per package, scoped to the package's path. Each directive is
+// written once; later packages using the same technology refer back to it.
+func writePackageSections(sb *strings.Builder, packages []stack.Package, units []unit) {
+	sb.WriteString("| Package | Stack |\n")
+	sb.WriteString("|---------|-------|\n")
+	for _, pkg := range packages {
+		sb.WriteString(fmt.Sprintf("| `%s` | %s |\n", pkg.Path, strings.Join(techLabels(pkg.Techs), ", ")))
+	}
+	sb.WriteString("\n")
+
+	byPackage := make(map[string]map[string]unit)
+	writtenIn := make(map[string]string) // directive body → package label
+	for _, u := range units {
+		if byPackage[u.Package] == nil {
+			byPackage[u.Package] = make(map[string]unit)
 		}
+		byPackage[u.Package][u.Tech.Name] = u
+	}
 
-		// Summary table
-		sb.WriteString("| Category | Technologies |\n")
-		sb.WriteString("|----------|-------------|\n")
-		for _, cat := range []string{"language", "runtime", "framework", "tool"} {
-			if len(categories[cat]) > 0 {
-				names := make([]string, len(categories[cat]))
-				for i, t := range categories[cat] {
-					names[i] = t.Name
+	for _, pkg := range packages {
+		var section strings.Builder
+		var seeAlso []string
+		for _, t := range pkg.Techs {
+			if u, ok := byPackage[pkg.Path][t.Name]; ok {
+				writtenIn[u.Body] = packageLabel(pkg.Path)
+				// Nest the directive under the package heading
+				section.WriteString("#")
+				section.WriteString(u.Body)
+				section.WriteString("\n\n")
+				continue
+			}
+			for _, u := range units {
+				if u.Tech.Name == t.Name && writtenIn[u.Body] != "" {
+					seeAlso = append(seeAlso, fmt.Sprintf("%s (see %s)", t.Name, writtenIn[u.Body]))
+					break
 				}
-				sb.WriteString(fmt.Sprintf("| %s | %s |\n", cat, strings.Join(names, ", ")))
 			}
 		}
-		sb.WriteString("\n---\n\n")
+		if len(seeAlso) > 0 {
+			section.WriteString(fmt.Sprintf("Also applies: %s\n\n", strings.Join(seeAlso, ", ")))
+		}
 
-		// Framework-specific directives
-		for _, t := range techs {
-			if directive, ok := frameworkDirectives[t.Name]; ok {
-				sb.WriteString(directive)
-				sb.WriteString("\n\n")
+		if pkg.Path == "." {
+			if section.Len() > 0 {
+				sb.WriteString("## Repository-wide\n\n")
+				sb.WriteString(section.String())
 			}
+			continue
 		}
+		sb.WriteString(fmt.Sprintf("## Package: %s\n\n", pkg.Path))
+		sb.WriteString(fmt.Sprintf("Applies to `%s/**`. Stack: %s\n\n", pkg.Path, strings.Join(techLabels(pkg.Techs), ", ")))
+		if !pkg.Commands.IsEmpty() {
+			sb.WriteString(fmt.Sprintf("Commands (run in `%s`): %s\n\n", pkg.Path, formatCommands(pkg.Commands)))
+		}
+		sb.WriteString(section.String())
 	}
+}
 
-	// Write docs-index.md
-	claudeDir := filepath.Join(projectRoot, ".claude")
-	if err := os.MkdirAll(claudeDir, 0o755); err != nil {
-		return nil, fmt.Errorf("creating .claude directory: %w", err)
+// directivesFor returns the directives for a technology. Directives from a
+// user-defined stack definition replace the built-in ones.
+func directivesFor(name string) []directive {
+	if def, ok := stack.LookupDefinition(name); ok && len(def.Directives) > 0 {
+		directives := make([]directive, len(def.Directives))
+		for i, d := range def.Directives {
+			directives[i] = directive{Versions: d.Versions, Body: d.Body}
+		}
+		return directives
 	}
+	return frameworkDirectives[name]
+}
 
-	indexPath := filepath.Join(claudeDir, DocsIndexFile)
-	if err := os.WriteFile(indexPath, []byte(sb.String()), 0o644); err != nil {
-		return nil, fmt.Errorf("writing docs-index.md: %w", err)
+// selectDirective picks the directive matching the technology's version.
+func selectDirective(t Tech) (string, bool) {
+	directives := directivesFor(t.Name)
+	if len(directives) == 0 {
+		return "", false
+	}
+	if t.Version == "" {
+		return directives[len(directives)-1].Body, true
 	}
+	for _, d := range directives {
+		if stack.MatchVersion(t.Version, d.Versions) {
+			return d.Body, true
+		}
+	}
+	return "", false
+}
 
-	// Write .docs-meta.json
-	meta := Meta{
-		GeneratedAt:    time.Now().UTC().Format(time.RFC3339),
-		DependencyHash: depHash,
-		DetectedStack:  techNames,
+// resolveDirective selects the directive for a technology and applies any
+// project-local override.
+func resolveDirective(t Tech, overrides map[string]override) (string, bool) {
+	directive, ok := selectDirective(t)
+	if o, has := overrides[t.Name]; has {
+		return o.apply(directive, ok)
 	}
-	metaData, err := json.MarshalIndent(meta, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("marshaling meta: %w", err)
+	return directive, ok
+}
+
+// formatCommands renders known commands, e.g. "test `go test ./...`, lint `go vet ./...`".
+func formatCommands(c stack.Commands) string {
+	var parts []string
+	for _, cmd := range []struct{ Name, Run string }{{"test", c.Test}, {"lint", c.Lint}, {"build", c.Build}} {
+		if cmd.Run != "" {
+			parts = append(parts, fmt.Sprintf("%s `%s`", cmd.Name, cmd.Run))
+		}
 	}
+	return strings.Join(parts, ", ")
+}
 
-	metaPath := filepath.Join(claudeDir, DocsMetaFile)
-	if err := os.WriteFile(metaPath, metaData, 0o644); err != nil {
-		return nil, fmt.Errorf("writing .docs-meta.json: %w", err)
+// techLabel renders a technology with its version, e.g. "nextjs 14.1.0".
+func techLabel(t Tech) string {
+	if t.Version == "" {
+		return t.Name
 	}
+	return t.Name + " " + t.Version
+}
 
-	return techNames, nil
+func techLabels(techs []Tech) []string {
+	labels := make([]string, len(techs))
+	for i, t := range techs {
+		labels[i] = techLabel(t)
+	}
+	return labels
+}
+
+// packageLabel names a package section for cross-references.
+func packageLabel(path string) string {
+	if path == "." {
+		return "Repository-wide"
+	}
+	return "`" + path + "`"
+}
+
+func pkgTechNames(pkg stack.Package) []string {
+	names := make([]string, len(pkg.Techs))
+	for i, t := range pkg.Techs {
+		names[i] = t.Name
+	}
+	return names
 }
 
 // IsStale checks if the docs-index needs regeneration.
 // Returns true if:
 // - .docs-meta.json doesn't exist
 // - Dependency hash has changed
-// - Last generation was more than StaleDays ago
-func IsStale(projectRoot string) (bool, string) {
-	metaPath := filepath.Join(projectRoot, ".claude", DocsMetaFile)
-	data, err := os.ReadFile(metaPath)
-	if err != nil {
+// - The detected stack has changed (e.g. a new k8s/ directory or version)
+// - Files in .claude/docs-overrides/ have changed
+// - The template's stack definitions have changed
+// - It was generated by a different ck version
+// - Last generation was more than the max age ago (StaleDays by default)
+func IsStale(projectRoot string, opts Options) (bool, string) {
+	meta, err := LoadMeta(projectRoot)
+	if os.IsNotExist(err) {
 		return true, "docs-index not yet generated"
 	}
-
-	var meta Meta
-	if err := json.Unmarshal(data, &meta); err != nil {
+	if err != nil {
 		return true, "corrupted .docs-meta.json"
 	}
 
@@ -301,19 +740,122 @@ func IsStale(projectRoot string) (bool, string) {
 		return true, "dependency files have changed"
 	}
 
+	// Check detected technologies, which also covers directory markers
+	if diff := stackDiff(meta, stack.DetectStack(projectRoot)); diff != "" {
+		return true, "detected stack has changed: " + diff
+	}
+
+	// Check directive sources
+	if ComputeOverridesHash(projectRoot) != meta.OverridesHash {
+		return true, "docs overrides have changed"
+	}
+	if ComputeDefinitionsHash(opts.TemplateDir) != meta.DefinitionsHash {
+		return true, "template stack definitions have changed"
+	}
+
+	// Check generator version
+	if opts.Version != "" && opts.Version != meta.GeneratorVersion {
+		if meta.GeneratorVersion == "" {
+			return true, fmt.Sprintf("generated by an unknown ck version, now %s", opts.Version)
+		}
+		return true, fmt.Sprintf("generated by ck %s, now %s", meta.GeneratorVersion, opts.Version)
+	}
+
 	// Check age
 	genTime, err := time.Parse(time.RFC3339, meta.GeneratedAt)
 	if err != nil {
 		return true, "invalid timestamp in .docs-meta.json"
 	}
 
+	maxAge := opts.MaxAgeDays
+	if maxAge <= 0 {
+		maxAge = meta.MaxAgeDays
+	}
+	if maxAge <= 0 {
+		maxAge = StaleDays
+	}
 	age := time.Since(genTime)
-	if age > time.Duration(StaleDays)*24*time.Hour {
-		return true, fmt.Sprintf("docs-index is %d days old (threshold: %d days)", int(age.Hours()/24), StaleDays)
+	if age > time.Duration(maxAge)*24*time.Hour {
+		return true, fmt.Sprintf("docs-index is %d days old (threshold: %d days)", int(age.Hours()/24), maxAge)
 	}
 
 	return false, ""
 }
 
+// stackDiff describes added, removed and re-versioned technologies, e.g.
+// "+kubernetes, -vue, nextjs 13.5.0→14.1.0". Returns "" when unchanged.
+func stackDiff(meta *Meta, techs []Tech) string {
+	before := make(map[string]bool, len(meta.DetectedStack))
+	for _, name := range meta.DetectedStack {
+		before[name] = true
+	}
+
+	var changes []string
+	now := make(map[string]bool, len(techs))
+	for _, t := range techs {
+		now[t.Name] = true
+		switch old := meta.Versions[t.Name]; {
+		case !before[t.Name]:
+			changes = append(changes, "+"+t.Name)
+		case old != t.Version:
+			changes = append(changes, fmt.Sprintf("%s %s→%s", t.Name, versionOrNone(old), versionOrNone(t.Version)))
+		}
+	}
+	for _, name := range meta.DetectedStack {
+		if !now[name] {
+			changes = append(changes, "-"+name)
+		}
+	}
+	return strings.Join(changes, ", ")
+}
+
+func versionOrNone(v string) string {
+	if v == "" {
+		return "?"
+	}
+	return v
+}
+
+// ComputeDefinitionsHash hashes the stack definition files that supply
+// directives: those registered with the stack package and every file in
+// templateDir's stacks/ directory, including ones that failed to load.
+// Returns "" if there are none.
+func ComputeDefinitionsHash(templateDir string) string {
+	seen := make(map[string]bool)
+	var sources []string
+	add := func(src string) {
+		if src != "" && !seen[src] {
+			seen[src] = true
+			sources = append(sources, src)
+		}
+	}
+	for _, def := range stack.Definitions() {
+		add(def.Source)
+	}
+	if templateDir != "" {
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(templateDir, stack.StacksDirName, pattern))
+			for _, m := range matches {
+				add(m)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return ""
+	}
+	sort.Strings(sources)
+
+	h := sha256.New()
+	for _, src := range sources {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			continue
+		}
+		h.Write([]byte(filepath.Base(src)))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
 // Type alias for external use
 type Tech = stack.Tech
@@ -0,0 +1,233 @@
+package docsindex
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeProject creates files (slash paths to contents) in a new temporary
+// project directory and returns it.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readIndex returns the generated docs-index.md.
+func readIndex(t *testing.T, projectRoot string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(projectRoot, ".claude", DocsIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSelectDirective(t *testing.T) {
+	tests := []struct {
+		tech Tech
+		want string // first line of the selected directive
+	}{
+		{Tech{Name: "nextjs", Version: "12.3.4"}, "## Next.js (Pages Router)"},
+		{Tech{Name: "nextjs", Version: "13.0.0"}, "## Next.js"},
+		{Tech{Name: "nextjs", Version: "14.1.0"}, "## Next.js"},
+		{Tech{Name: "nextjs"}, "## Next.js"}, // unknown version: the current major
+		{Tech{Name: "vue", Version: "2.7.16"}, "## Vue 2"},
+		{Tech{Name: "svelte", Version: "5.0.0"}, "## Svelte"},
+	}
+	for _, tt := range tests {
+		body, ok := selectDirective(tt.tech)
+		first, _, _ := strings.Cut(body, "\n")
+		if !ok || first != tt.want {
+			t.Errorf("selectDirective(%s %s) = %q, %v, want %q", tt.tech.Name, tt.tech.Version, first, ok, tt.want)
+		}
+	}
+	if _, ok := selectDirective(Tech{Name: "no-such-tech", Version: "1.0"}); ok {
+		t.Error("want no directive for an unknown technology")
+	}
+}
+
+func TestGenerateLockedVersion(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"package.json":      `{"dependencies": {"next": "^12.3.0"}}`,
+		"package-lock.json": `{"packages": {"node_modules/next": {"version": "12.3.4"}}}`,
+	})
+	if _, err := Generate(root, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	index := readIndex(t, root)
+	if !strings.Contains(index, "nextjs 12.3.4") || !strings.Contains(index, "## Next.js (Pages Router)") {
+		t.Errorf("docs-index does not use the <13 directive for next 12.3.4:\n%s", index)
+	}
+	if strings.Contains(index, "App Router") {
+		t.Errorf("docs-index includes the >=13 directive:\n%s", index)
+	}
+	meta, err := LoadMeta(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Versions["nextjs"] != "12.3.4" {
+		t.Errorf("meta versions = %v", meta.Versions)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	const version = "1.2.0"
+	files := map[string]string{
+		"package.json": `{"dependencies": {"next": "14.1.0"}}`,
+	}
+	// generate writes a fresh index, then lets change alter the project,
+	// the template directory or the recorded metadata.
+	generate := func(t *testing.T, change func(t *testing.T, root, tmplDir string, meta *Meta)) (string, Options) {
+		t.Helper()
+		root := writeProject(t, files)
+		opts := Options{Version: version, TemplateDir: writeProject(t, map[string]string{"stacks/acme.yaml": "name: acme\n"})}
+		if _, err := Generate(root, opts); err != nil {
+			t.Fatal(err)
+		}
+		meta, err := LoadMeta(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		change(t, root, opts.TemplateDir, meta)
+		data, err := json.Marshal(meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, ".claude", DocsMetaFile), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return root, opts
+	}
+	write := func(t *testing.T, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	daysAgo := func(days int) string {
+		return time.Now().Add(-time.Duration(days) * 24 * time.Hour).UTC().Format(time.RFC3339)
+	}
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, root, tmplDir string, meta *Meta)
+		opts   func(*Options)
+		want   string // "" when fresh
+	}{
+		{
+			name:   "fresh",
+			change: func(*testing.T, string, string, *Meta) {},
+		},
+		{
+			name: "dependencies",
+			change: func(t *testing.T, root, _ string, _ *Meta) {
+				write(t, filepath.Join(root, "package.json"), `{"dependencies": {"next": "14.2.0"}}`)
+			},
+			want: "dependency files have changed",
+		},
+		{
+			name: "stack",
+			change: func(_ *testing.T, _, _ string, meta *Meta) {
+				meta.DetectedStack = append(meta.DetectedStack, "vue")
+				meta.Versions["nextjs"] = "13.5.6"
+			},
+			want: "detected stack has changed: nextjs 13.5.6→14.1.0, -vue",
+		},
+		{
+			name: "overrides",
+			change: func(t *testing.T, root, _ string, _ *Meta) {
+				write(t, filepath.Join(root, ".claude", OverridesDir, "nextjs.md"), "## Ours\n")
+			},
+			want: "docs overrides have changed",
+		},
+		{
+			name: "definitions",
+			change: func(t *testing.T, _, tmplDir string, _ *Meta) {
+				write(t, filepath.Join(tmplDir, "stacks", "acme.yaml"), "name: acme\ncategory: tool\n")
+			},
+			want: "template stack definitions have changed",
+		},
+		{
+			name:   "version",
+			change: func(*testing.T, string, string, *Meta) {},
+			opts:   func(o *Options) { o.Version = "1.3.0" },
+			want:   "generated by ck 1.2.0, now 1.3.0",
+		},
+		{
+			name:   "unknown version",
+			change: func(_ *testing.T, _, _ string, meta *Meta) { meta.GeneratorVersion = "" },
+			want:   "generated by an unknown ck version, now 1.2.0",
+		},
+		{
+			name:   "timestamp",
+			change: func(_ *testing.T, _, _ string, meta *Meta) { meta.GeneratedAt = "yesterday" },
+			want:   "invalid timestamp in .docs-meta.json",
+		},
+		{
+			name:   "age",
+			change: func(_ *testing.T, _, _ string, meta *Meta) { meta.GeneratedAt = daysAgo(StaleDays + 1) },
+			want:   "docs-index is 15 days old (threshold: 14 days)",
+		},
+		{
+			name: "recorded max age",
+			change: func(_ *testing.T, _, _ string, meta *Meta) {
+				meta.GeneratedAt = daysAgo(4)
+				meta.MaxAgeDays = 3
+			},
+			want: "docs-index is 4 days old (threshold: 3 days)",
+		},
+		{
+			name: "max age option",
+			change: func(_ *testing.T, _, _ string, meta *Meta) {
+				meta.GeneratedAt = daysAgo(20)
+				meta.MaxAgeDays = 3
+			},
+			opts: func(o *Options) { o.MaxAgeDays = 30 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, opts := generate(t, tt.change)
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			stale, reason := IsStale(root, opts)
+			if stale != (tt.want != "") || reason != tt.want {
+				t.Errorf("IsStale = %v, %q, want %q", stale, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsStaleMissingMeta(t *testing.T) {
+	root := writeProject(t, map[string]string{"go.mod": "module m\n"})
+	if stale, reason := IsStale(root, Options{}); !stale || reason != "docs-index not yet generated" {
+		t.Errorf("IsStale = %v, %q", stale, reason)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".claude"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".claude", DocsMetaFile), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if stale, reason := IsStale(root, Options{}); !stale || reason != "corrupted .docs-meta.json" {
+		t.Errorf("IsStale = %v, %q", stale, reason)
+	}
+}
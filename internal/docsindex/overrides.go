@@ -0,0 +1,132 @@
+package docsindex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// OverridesDir holds project-local directive overrides inside .claude/, one
+// markdown file per technology, e.g. .claude/docs-overrides/vue.md:
+//
+//	---
+//	mode: append
+//	---
+//	- Zustand for global state, not Pinia
+const OverridesDir = "docs-overrides"
+
+// Override modes.
+const (
+	ModeReplace  = "replace"  // use the override body instead of the built-in directive
+	ModeAppend   = "append"   // add the override body after the built-in directive
+	ModeSuppress = "suppress" // emit no directive for the technology
+)
+
+// override is a parsed docs-overrides/<tech>.md file.
+type override struct {
+	Mode string
+	Body string
+}
+
+// loadOverrides reads .claude/docs-overrides/*.md keyed by technology name.
+// A missing directory yields no overrides. The mode defaults to replace.
+func loadOverrides(projectRoot string) (map[string]override, error) {
+	dir := filepath.Join(projectRoot, ".claude", OverridesDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", OverridesDir, err)
+	}
+
+	overrides := make(map[string]override)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".md" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s/%s: %w", OverridesDir, e.Name(), err)
+		}
+
+		o := override{Mode: ModeReplace, Body: string(data)}
+		if front, body, ok := catalog.SplitFrontmatter(data); ok {
+			var fm struct {
+				Mode string `yaml:"mode"`
+			}
+			if err := yaml.Unmarshal(front, &fm); err != nil {
+				return nil, fmt.Errorf("parsing %s/%s: %w", OverridesDir, e.Name(), err)
+			}
+			if fm.Mode != "" {
+				o.Mode = fm.Mode
+			}
+			o.Body = string(body)
+		}
+
+		switch o.Mode {
+		case ModeReplace, ModeAppend, ModeSuppress:
+		default:
+			return nil, fmt.Errorf("%s/%s: unknown mode %q (want %s, %s or %s)",
+				OverridesDir, e.Name(), o.Mode, ModeReplace, ModeAppend, ModeSuppress)
+		}
+		o.Body = strings.Trim(o.Body, "\n")
+		overrides[strings.TrimSuffix(e.Name(), ".md")] = o
+	}
+	return overrides, nil
+}
+
+// apply combines a built-in directive with the override for its technology.
+func (o override) apply(directive string, ok bool) (string, bool) {
+	switch o.Mode {
+	case ModeSuppress:
+		return "", false
+	case ModeAppend:
+		if ok {
+			return directive + "\n" + o.Body, true
+		}
+	}
+	return o.Body, o.Body != ""
+}
+
+// overrideModes summarises overrides for .docs-meta.json.
+func overrideModes(overrides map[string]override) map[string]string {
+	if len(overrides) == 0 {
+		return nil
+	}
+	modes := make(map[string]string, len(overrides))
+	for name, o := range overrides {
+		modes[name] = o.Mode
+	}
+	return modes
+}
+
+// ComputeOverridesHash hashes the files in .claude/docs-overrides/ so that
+// editing an override marks the docs index stale. Returns "" if there are none.
+func ComputeOverridesHash(projectRoot string) string {
+	dir := filepath.Join(projectRoot, ".claude", OverridesDir)
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.md"))
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+
+	h := sha256.New()
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			continue
+		}
+		h.Write([]byte(filepath.Base(m)))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
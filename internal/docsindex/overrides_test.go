@@ -0,0 +1,93 @@
+package docsindex
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOverrideApply(t *testing.T) {
+	const builtin = "## Vue\n- Composition API"
+	tests := []struct {
+		o        override
+		ok       bool // a built-in directive exists
+		want     string
+		wantKept bool
+	}{
+		{override{Mode: ModeReplace, Body: "## Vue (ours)"}, true, "## Vue (ours)", true},
+		{override{Mode: ModeReplace, Body: "## Vue (ours)"}, false, "## Vue (ours)", true},
+		{override{Mode: ModeReplace}, true, "", false},
+		{override{Mode: ModeAppend, Body: "- Pinia for state"}, true, builtin + "\n- Pinia for state", true},
+		{override{Mode: ModeAppend, Body: "- Pinia for state"}, false, "- Pinia for state", true},
+		{override{Mode: ModeSuppress, Body: "ignored"}, true, "", false},
+	}
+	for _, tt := range tests {
+		directive := ""
+		if tt.ok {
+			directive = builtin
+		}
+		got, kept := tt.o.apply(directive, tt.ok)
+		if got != tt.want || kept != tt.wantKept {
+			t.Errorf("%+v.apply(ok=%v) = %q, %v, want %q, %v", tt.o, tt.ok, got, kept, tt.want, tt.wantKept)
+		}
+	}
+}
+
+func TestGenerateOverrides(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"package.json": `{"dependencies": {"next": "14.1.0", "vue": "3.4.0", "svelte": "5.0.0"}}`,
+		// No front matter: the whole file replaces the directive
+		".claude/docs-overrides/nextjs.md": "## Next.js (house rules)\n- Pages live in src/app\n",
+		".claude/docs-overrides/vue.md":    "---\nmode: append\n---\n- Zustand for global state, not Pinia\n",
+		".claude/docs-overrides/svelte.md": "---\nmode: suppress\n---\n",
+	})
+	if _, err := Generate(root, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	index := readIndex(t, root)
+
+	for _, want := range []string{
+		"## Next.js (house rules)\n- Pages live in src/app",
+		"## Vue\n",
+		"- Zustand for global state, not Pinia",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("docs-index missing %q:\n%s", want, index)
+		}
+	}
+	for _, unwanted := range []string{"App Router", "## Svelte"} {
+		if strings.Contains(index, unwanted) {
+			t.Errorf("docs-index contains %q:\n%s", unwanted, index)
+		}
+	}
+	// The appended note follows the built-in Vue directive
+	if strings.Index(index, "## Vue") > strings.Index(index, "Zustand") {
+		t.Errorf("append override precedes the built-in directive:\n%s", index)
+	}
+
+	meta, err := LoadMeta(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(meta.DetectedStack, "svelte") {
+		t.Errorf("stack = %v; a suppressed technology is still detected", meta.DetectedStack)
+	}
+	want := map[string]string{"nextjs": ModeReplace, "vue": ModeAppend, "svelte": ModeSuppress}
+	if !reflect.DeepEqual(meta.Overrides, want) {
+		t.Errorf("meta overrides = %v, want %v", meta.Overrides, want)
+	}
+	if meta.OverridesHash == "" || meta.OverridesHash != ComputeOverridesHash(root) {
+		t.Errorf("overrides hash = %q", meta.OverridesHash)
+	}
+}
+
+func TestLoadOverridesUnknownMode(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".claude/docs-overrides/vue.md": "---\nmode: prepend\n---\nbody\n",
+	})
+	_, err := loadOverrides(root)
+	if err == nil || !strings.Contains(err.Error(), `unknown mode "prepend"`) {
+		t.Errorf("err = %v, want unknown mode", err)
+	}
+}
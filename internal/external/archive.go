@@ -0,0 +1,197 @@
+package external
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArchiveBytes caps the size of a downloaded archive.
+	maxArchiveBytes = 64 << 20
+	// maxExtractBytes caps the total size of the files extracted from it.
+	maxExtractBytes = 256 << 20
+)
+
+// fetchArchive downloads a tarball or zip and extracts it into dir. It
+// returns the archive root: dir, or its only top-level directory when the
+// archive wraps everything in one (as forge-generated archives do).
+func fetchArchive(ctx context.Context, rawURL, dir string) (string, error) {
+	data, err := download(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	u, _ := url.Parse(rawURL)
+	name := strings.ToLower(u.Path)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		err = extractZip(data, dir)
+	case strings.HasSuffix(name, ".tar"):
+		err = extractTar(bytes.NewReader(data), dir)
+	default:
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(bytes.NewReader(data)); err == nil {
+			err = extractTar(gz, dir)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("extracting %s: %w", rawURL, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+// download reads an http(s) or file:// URL, up to maxArchiveBytes.
+func download(ctx context.Context, rawURL string) ([]byte, error) {
+	var body io.Reader
+	if p, ok := strings.CutPrefix(rawURL, "file://"); ok {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		body = f
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := &http.Client{Timeout: 60 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", rawURL, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: status %d", rawURL, resp.StatusCode)
+		}
+		body = resp.Body
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxArchiveBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveBytes {
+		return nil, fmt.Errorf("fetching %s: larger than %d bytes", rawURL, maxArchiveBytes)
+	}
+	return data, nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > maxExtractBytes {
+				return fmt.Errorf("contents larger than %d bytes", maxExtractBytes)
+			}
+			if err := writeArchiveFile(target, tr, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		}
+		// Links and special files are skipped
+	}
+}
+
+func extractZip(data []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range zr.File {
+		target, err := archiveTarget(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			total += int64(f.UncompressedSize64)
+			if total > maxExtractBytes {
+				return fmt.Errorf("contents larger than %d bytes", maxExtractBytes)
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeArchiveFile(target, rc, mode)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// archiveTarget maps an archive entry name into dir, rejecting names that
+// would land outside it.
+func archiveTarget(dir, name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	for _, seg := range strings.Split(name, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("unsafe entry %q", name)
+		}
+	}
+	return filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name))), nil
+}
+
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, io.LimitReader(r, maxExtractBytes)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
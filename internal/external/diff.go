@@ -0,0 +1,37 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Diff returns a unified diff from the installed component in targetDir to
+// the staged one, or "" when they are identical. Paths are shown relative
+// to the .claude directory.
+func (q *Quarantine) Diff(ctx context.Context, targetDir string) (string, error) {
+	installed, staged := q.Kind.Path(targetDir, q.Name), q.Path()
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-index", "--no-color", "--src-prefix=a/", "--dst-prefix=b/", "--", installed, staged)
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return "", err
+	}
+
+	// git shows the absolute paths without their leading "/". The prefix
+	// tells the sides apart: a new file has the staged path on both.
+	rel, err := filepath.Rel(targetDir, installed)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	installed, staged = strings.TrimPrefix(installed, "/"), strings.TrimPrefix(staged, "/")
+	return strings.NewReplacer(
+		"a/"+installed, "a/"+rel,
+		"b/"+installed, "b/"+rel,
+		"a/"+staged, "a/"+rel,
+		"b/"+staged, "b/"+rel,
+	).Replace(string(out)), nil
+}
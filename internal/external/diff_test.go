@@ -0,0 +1,95 @@
+package external
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+func TestDiff(t *testing.T) {
+	setHome(t)
+	repo, _ := gitRepo(t)
+	dir := strings.TrimPrefix(repo, "file://")
+	target := t.TempDir()
+	ctx := context.Background()
+	fetch := func(kind, name, path string) *Quarantine {
+		t.Helper()
+		q, err := FetchLocation(ctx, kind, name, Location{Repo: repo, Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(q.Discard)
+		return q
+	}
+	for _, q := range []*Quarantine{fetch("agents", "dba", "agents/dba.md"), fetch("skills", "sqlx", "skills/sqlx")} {
+		if err := q.Install(target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff, err := fetch("agents", "dba", "agents/dba.md").Diff(ctx, target); err != nil || diff != "" {
+		t.Errorf("Diff of an unchanged component = %q, %v", diff, err)
+	}
+
+	commitFiles(t, dir, map[string]string{
+		"agents/dba.md":        "changed\n",
+		"skills/sqlx/new.md":   "new\n",
+		"skills/sqlx/SKILL.md": "---\nname: sqlx\n---\nSkill v2\n",
+	})
+	runGit(t, dir, "rm", "--quiet", "skills/sqlx/examples.md")
+	runGit(t, dir, "commit", "--quiet", "-m", "remove examples")
+
+	tests := []struct {
+		q       *Quarantine
+		headers []string
+	}{
+		{fetch("agents", "dba", "agents/dba.md"), []string{
+			"diff --git a/agents/dba.md b/agents/dba.md",
+			"--- a/agents/dba.md",
+			"+++ b/agents/dba.md",
+		}},
+		{fetch("skills", "sqlx", "skills/sqlx"), []string{
+			"diff --git a/skills/sqlx/SKILL.md b/skills/sqlx/SKILL.md",
+			"--- a/skills/sqlx/SKILL.md",
+			"+++ b/skills/sqlx/SKILL.md",
+			"diff --git a/skills/sqlx/examples.md b/skills/sqlx/examples.md",
+			"--- a/skills/sqlx/examples.md",
+			"+++ /dev/null",
+			"diff --git a/skills/sqlx/new.md b/skills/sqlx/new.md",
+			"--- /dev/null",
+			"+++ b/skills/sqlx/new.md",
+		}},
+	}
+	for _, tt := range tests {
+		diff, err := tt.q.Diff(ctx, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var headers []string
+		for _, line := range strings.Split(diff, "\n") {
+			if strings.HasPrefix(line, "diff ") || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+				headers = append(headers, line)
+			}
+		}
+		if strings.Join(headers, "\n") != strings.Join(tt.headers, "\n") {
+			t.Errorf("%s headers =\n%s\nwant\n%s", tt.q.Name, strings.Join(headers, "\n"), strings.Join(tt.headers, "\n"))
+		}
+		if strings.Contains(diff, target) || strings.Contains(diff, tt.q.Dir) {
+			t.Errorf("%s diff shows absolute paths:\n%s", tt.q.Name, diff)
+		}
+	}
+
+	// The new commit is recorded once the update is installed
+	q := tests[0].q
+	if err := q.Install(target); err != nil {
+		t.Fatal(err)
+	}
+	p, err := catalog.ReadProvenance(target, "agents", "dba")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := runGit(t, dir, "rev-parse", "HEAD"); p.Commit != want {
+		t.Errorf("provenance commit = %s, want %s", p.Commit, want)
+	}
+}
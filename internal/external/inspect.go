@@ -0,0 +1,91 @@
+package external
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// File describes a file in a quarantined component.
+type File struct {
+	Path       string // relative to the quarantine, e.g. "skills/foo/run.sh"
+	Size       int64
+	Mode       fs.FileMode
+	Executable bool // any execute bit set
+	Script     bool // a script by extension or shebang
+	Binary     bool // not UTF-8 text
+	Symlink    bool
+}
+
+// Suspicious reports whether the file deserves attention in a review.
+func (f File) Suspicious() bool {
+	return f.Executable || f.Script || f.Binary || f.Symlink
+}
+
+var scriptExts = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".fish": true,
+	".py": true, ".js": true, ".mjs": true, ".cjs": true, ".ts": true,
+	".rb": true, ".pl": true, ".php": true, ".ps1": true, ".bat": true, ".cmd": true,
+}
+
+// Files lists the staged files, sorted by path.
+func (q *Quarantine) Files() ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(q.Path(), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(q.Dir, p)
+		f := File{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			Mode:    info.Mode(),
+			Symlink: info.Mode()&fs.ModeSymlink != 0,
+		}
+		if info.Mode().IsRegular() {
+			f.Executable = info.Mode().Perm()&0o111 != 0
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			f.Binary = isBinary(data)
+			f.Script = scriptExts[strings.ToLower(filepath.Ext(p))] || bytes.HasPrefix(data, []byte("#!"))
+		}
+		files = append(files, f)
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, err
+}
+
+// ReadFile returns the content of a staged file by its File.Path.
+func (q *Quarantine) ReadFile(rel string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(q.Dir, filepath.FromSlash(rel)))
+}
+
+func isBinary(data []byte) bool {
+	sample := data[:min(len(data), 8000)]
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(trimPartialRune(sample))
+}
+
+// trimPartialRune drops a rune cut off at the end of a sample.
+func trimPartialRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if utf8.Valid(b) {
+			return b
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,172 @@
+package external
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Location is a path inside a git repository at a given ref, or inside an
+// archive.
+type Location struct {
+	Repo    string // git URL (https, ssh, git or file); empty for archives
+	Archive string // tarball or zip URL; empty for repositories
+	Ref     string // branch, tag or commit; "" for the default branch
+	Path    string // inside the repository or archive; "" for the root
+}
+
+func (l Location) String() string {
+	s := l.Repo
+	if l.Archive != "" {
+		s = l.Archive
+	}
+	if l.Ref != "" {
+		s += "@" + l.Ref
+	}
+	if l.Path != "" {
+		s += "#" + l.Path
+	}
+	return s
+}
+
+// Resolve maps a component URL to a Location. It understands:
+//
+//	https://github.com/org/repo/tree/main/skills/foo     (GitHub: tree, blob, raw)
+//	https://gitlab.com/group/sub/repo/-/blob/v1/a.md     (GitLab: tree, blob, raw)
+//	https://bitbucket.org/team/repo/src/main/skills/foo  (Bitbucket: src, raw)
+//	https://codeberg.org/org/repo/src/branch/main/foo    (Gitea/Forgejo: src, raw)
+//	git+https://host/org/repo.git@v1.2#skills/foo        (any git remote)
+//	git+ssh://git@host/org/repo.git#agents/bar.md
+//	git://localhost/repo, ssh://host/repo, file:///srv/repo (also with @ref#path)
+//	https://host/pkg.tar.gz#skills/foo                   (.tar.gz, .tgz, .tar, .zip)
+//
+// Any other http(s) URL with an owner and repository is treated as a git
+// remote, e.g. https://github.com/org/repo.
+func Resolve(rawURL string) (Location, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Location{}, fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+
+	if isArchive(u.Path) {
+		if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file" {
+			return Location{}, fmt.Errorf("%w: %s", ErrUnsupported, rawURL)
+		}
+		path := u.Fragment
+		u.Fragment = ""
+		return Location{Archive: u.String(), Path: cleanRepoPath(path)}, nil
+	}
+
+	switch {
+	case strings.HasPrefix(u.Scheme, "git+"):
+		u.Scheme = strings.TrimPrefix(u.Scheme, "git+")
+		return gitURL(u)
+	case u.Scheme == "git" || u.Scheme == "ssh" || u.Scheme == "file":
+		return gitURL(u)
+	case u.Scheme == "http" || u.Scheme == "https":
+		return forgeURL(u)
+	}
+	return Location{}, fmt.Errorf("%w: %s", ErrUnsupported, rawURL)
+}
+
+var archiveSuffixes = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+func isArchive(path string) bool {
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(strings.ToLower(path), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitURL reads a plain git remote with an optional "@ref" after the last
+// path segment and an optional "#path" fragment.
+func gitURL(u *url.URL) (Location, error) {
+	loc := Location{Path: cleanRepoPath(u.Fragment)}
+	u.Fragment = ""
+	if at := strings.LastIndex(u.Path, "@"); at > strings.LastIndex(u.Path, "/") {
+		loc.Ref = u.Path[at+1:]
+		u.Path = u.Path[:at]
+	}
+	if err := CheckRef(loc.Ref); err != nil {
+		return Location{}, err
+	}
+	loc.Repo = u.String()
+	return loc, nil
+}
+
+// forgeURL reads the web URLs of the common git hosts. The forge is told
+// by the URL layout rather than the host name, so self-hosted GitLab,
+// Gitea and Forgejo instances work too.
+func forgeURL(u *url.URL) (Location, error) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	host := strings.TrimPrefix(u.Host, "www.")
+	repo := func(segments []string) string {
+		return u.Scheme + "://" + u.Host + "/" + strings.Join(segments, "/")
+	}
+	at := func(segments []string, ref string, path []string) (Location, error) {
+		if ref == "" {
+			return Location{}, fmt.Errorf("%s: missing ref", u)
+		}
+		if err := CheckRef(ref); err != nil {
+			return Location{}, err
+		}
+		return Location{Repo: repo(segments), Ref: ref, Path: cleanRepoPath(strings.Join(path, "/"))}, nil
+	}
+
+	// GitLab: <group/.../project>/-/(tree|blob|raw)/<ref>/<path>
+	for i, p := range parts {
+		if p == "-" && i >= 2 && i+2 < len(parts) && (parts[i+1] == "tree" || parts[i+1] == "blob" || parts[i+1] == "raw") {
+			return at(parts[:i], parts[i+2], parts[i+3:])
+		}
+	}
+
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Location{}, fmt.Errorf("%w: %s", ErrUnsupported, u)
+	}
+	owner := parts[:2]
+
+	switch {
+	case host == "raw.githubusercontent.com" && len(parts) >= 3:
+		// raw.githubusercontent.com/<owner>/<repo>/<ref>/<path>
+		u.Host = "github.com"
+		return at(owner, parts[2], parts[3:])
+	case len(parts) >= 4 && (parts[2] == "tree" || parts[2] == "blob"):
+		// GitHub
+		return at(owner, parts[3], parts[4:])
+	case len(parts) >= 5 && (parts[2] == "src" || parts[2] == "raw") && (parts[3] == "branch" || parts[3] == "tag" || parts[3] == "commit"):
+		// Gitea and Forgejo
+		return at(owner, parts[4], parts[5:])
+	case len(parts) >= 4 && (parts[2] == "src" || parts[2] == "raw"):
+		// Bitbucket
+		return at(owner, parts[3], parts[4:])
+	}
+	return gitURL(u)
+}
+
+var commitSHA = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// CheckRef rejects a ref that git could read as an option or that is not
+// a valid branch or tag name. Full commit SHAs and "" (the default
+// branch) are accepted.
+func CheckRef(ref string) error {
+	if ref == "" || commitSHA.MatchString(ref) {
+		return nil
+	}
+	if strings.HasPrefix(ref, "-") || exec.Command("git", "check-ref-format", "--allow-onelevel", ref).Run() != nil {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	return nil
+}
+
+// cleanRepoPath normalises a path inside a repository.
+func cleanRepoPath(p string) string {
+	p = strings.Trim(p, "/")
+	if p == "." {
+		return ""
+	}
+	return p
+}
@@ -0,0 +1,97 @@
+package external
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		url  string
+		want Location
+	}{
+		// GitHub
+		{"https://github.com/org/repo/tree/main/skills/foo", Location{Repo: "https://github.com/org/repo", Ref: "main", Path: "skills/foo"}},
+		{"https://github.com/org/repo/blob/v1.2/agents/bar.md", Location{Repo: "https://github.com/org/repo", Ref: "v1.2", Path: "agents/bar.md"}},
+		{"https://github.com/org/repo/tree/main", Location{Repo: "https://github.com/org/repo", Ref: "main"}},
+		{"https://raw.githubusercontent.com/org/repo/main/agents/bar.md", Location{Repo: "https://github.com/org/repo", Ref: "main", Path: "agents/bar.md"}},
+		{"https://github.com/org/repo", Location{Repo: "https://github.com/org/repo"}},
+		// GitLab, including subgroups
+		{"https://gitlab.com/group/sub/repo/-/blob/v1/a.md", Location{Repo: "https://gitlab.com/group/sub/repo", Ref: "v1", Path: "a.md"}},
+		{"https://gitlab.example.com/team/repo/-/tree/main/skills/foo", Location{Repo: "https://gitlab.example.com/team/repo", Ref: "main", Path: "skills/foo"}},
+		{"https://gitlab.com/team/repo/-/raw/" + sha + "/a.md", Location{Repo: "https://gitlab.com/team/repo", Ref: sha, Path: "a.md"}},
+		// Bitbucket
+		{"https://bitbucket.org/team/repo/src/main/skills/foo", Location{Repo: "https://bitbucket.org/team/repo", Ref: "main", Path: "skills/foo"}},
+		// Gitea and Forgejo
+		{"https://codeberg.org/org/repo/src/branch/main/skills/foo", Location{Repo: "https://codeberg.org/org/repo", Ref: "main", Path: "skills/foo"}},
+		{"https://git.example.com/org/repo/raw/tag/v2/agents/a.md", Location{Repo: "https://git.example.com/org/repo", Ref: "v2", Path: "agents/a.md"}},
+		// Any git remote
+		{"git+https://host/org/repo.git@v1.2#skills/foo", Location{Repo: "https://host/org/repo.git", Ref: "v1.2", Path: "skills/foo"}},
+		{"git+https://host/org/repo.git", Location{Repo: "https://host/org/repo.git"}},
+		{"git+ssh://git@host/org/repo.git#agents/bar.md", Location{Repo: "ssh://git@host/org/repo.git", Path: "agents/bar.md"}},
+		{"git+ssh://git@host/org/repo.git@" + sha, Location{Repo: "ssh://git@host/org/repo.git", Ref: sha}},
+		{"git://localhost/repo@main#/skills/foo/", Location{Repo: "git://localhost/repo", Ref: "main", Path: "skills/foo"}},
+		{"file:///srv/repo@feature/x#a.md", Location{Repo: "file:///srv/repo@feature/x", Path: "a.md"}},
+		{"file:///srv/repo@v1#a.md", Location{Repo: "file:///srv/repo", Ref: "v1", Path: "a.md"}},
+		// Archives
+		{"https://host/pkg.tar.gz#skills/foo", Location{Archive: "https://host/pkg.tar.gz", Path: "skills/foo"}},
+		{"https://host/pkg.TGZ", Location{Archive: "https://host/pkg.TGZ"}},
+		{"https://github.com/org/repo/archive/refs/heads/main.zip#skills/foo", Location{Archive: "https://github.com/org/repo/archive/refs/heads/main.zip", Path: "skills/foo"}},
+		{"file:///tmp/pkg.tar#agents/a.md", Location{Archive: "file:///tmp/pkg.tar", Path: "agents/a.md"}},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.url)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestResolveUnsupported(t *testing.T) {
+	for _, u := range []string{
+		"ftp://host/repo",
+		"https://example.com",
+		"https://example.com/only-owner",
+		"ssh://host/pkg.tar.gz",
+		"mailto:someone@example.com",
+	} {
+		if _, err := Resolve(u); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Resolve(%q) err = %v, want ErrUnsupported", u, err)
+		}
+	}
+}
+
+func TestResolveRejectsOptionRefs(t *testing.T) {
+	for _, u := range []string{
+		"git+https://host/org/repo.git@--upload-pack=touch%20pwned#a.md",
+		"file:///srv/repo@-q",
+		"https://github.com/org/repo/tree/--upload-pack=x/skills/foo",
+		"https://gitlab.com/team/repo/-/blob/--output=x/a.md",
+		"https://codeberg.org/org/repo/src/branch/-x/foo",
+		"git://localhost/repo@a..b",
+	} {
+		_, err := Resolve(u)
+		if err == nil || !strings.Contains(err.Error(), "invalid ref") {
+			t.Errorf("Resolve(%q) err = %v, want invalid ref", u, err)
+		}
+	}
+}
+
+func TestLocationString(t *testing.T) {
+	tests := map[Location]string{
+		{Repo: "https://host/r.git", Ref: "v1", Path: "a.md"}: "https://host/r.git@v1#a.md",
+		{Repo: "https://host/r.git"}:                          "https://host/r.git",
+		{Archive: "https://host/p.zip", Path: "skills/x"}:     "https://host/p.zip#skills/x",
+	}
+	for loc, want := range tests {
+		if got := loc.String(); got != want {
+			t.Errorf("%+v.String() = %q, want %q", loc, got, want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package external fetches components from outside the template directory
+// into a quarantine area, where they can be reviewed before installation.
+package external
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/config"
+)
+
+// maxFileBytes caps the size of a fetched single-file component.
+const maxFileBytes = 1 << 20
+
+// ErrUnsupported marks URLs that cannot be fetched automatically.
+var ErrUnsupported = errors.New("unsupported source")
+
+// Quarantine is a staged external component. Its directory mirrors the
+// .claude layout, so the component lives at Kind.Path(Dir, Name).
+type Quarantine struct {
+	Dir        string
+	Kind       catalog.Kind
+	Name       string
+	Provenance catalog.Provenance
+}
+
+// Path returns where the staged component lives.
+func (q *Quarantine) Path() string { return q.Kind.Path(q.Dir, q.Name) }
+
+// Fetch stages component name of type compType from a component URL. See
+// Resolve for the URLs understood and FetchLocation for what is staged.
+func Fetch(ctx context.Context, source, compType, name, rawURL string) (*Quarantine, error) {
+	loc, err := Resolve(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	q, err := FetchLocation(ctx, compType, name, loc)
+	if err != nil {
+		return nil, err
+	}
+	q.Provenance.Source = source
+	q.Provenance.URL = rawURL
+	return q, nil
+}
+
+// FetchLocation stages component name of type compType from loc. A
+// repository is checked out at the commit loc.Ref resolves to; an archive
+// is downloaded and extracted. Skills are a directory (the whole
+// repository or archive when loc.Path is empty) and must contain
+// SKILL.md; other kinds are a single file.
+func FetchLocation(ctx context.Context, compType, name string, loc Location) (*Quarantine, error) {
+	k, ok := catalog.LookupKind(compType)
+	if !ok {
+		return nil, fmt.Errorf("unknown component type: %s", compType)
+	}
+	// loc may come from a provenance record rather than Resolve
+	if err := CheckRef(loc.Ref); err != nil {
+		return nil, err
+	}
+
+	base := config.QuarantineDir()
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(base, strings.ReplaceAll(name, "/", "_")+"-*")
+	if err != nil {
+		return nil, err
+	}
+	q := &Quarantine{
+		Dir:  dir,
+		Kind: k,
+		Name: name,
+		Provenance: catalog.Provenance{
+			Repo:    loc.Repo,
+			Archive: loc.Archive,
+			Ref:     loc.Ref,
+			Path:    loc.Path,
+		},
+	}
+	if err := q.fetch(ctx, loc); err != nil {
+		q.Discard()
+		return nil, err
+	}
+	return q, nil
+}
+
+// fetch unpacks loc into a scratch directory and moves loc.Path into
+// place.
+func (q *Quarantine) fetch(ctx context.Context, loc Location) error {
+	scratch := filepath.Join(q.Dir, ".src")
+	defer os.RemoveAll(scratch)
+
+	root := scratch
+	if loc.Archive != "" {
+		var err error
+		if root, err = fetchArchive(ctx, loc.Archive, scratch); err != nil {
+			return err
+		}
+	} else {
+		commit, err := checkout(ctx, loc.Repo, loc.Ref, loc.Path, scratch)
+		if err != nil {
+			return err
+		}
+		q.Provenance.Commit = commit
+	}
+	// Links could point anywhere on the machine, including through a
+	// directory on loc.Path; archives never extract them
+	if err := removeSymlinks(root); err != nil {
+		return err
+	}
+
+	src := filepath.Join(root, filepath.FromSlash(loc.Path))
+	if rel, err := filepath.Rel(root, src); err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("%s: path escapes the repository", loc)
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("%s: not found", loc)
+	}
+
+	if q.Kind.Name() == "skills" {
+		if !info.IsDir() {
+			return fmt.Errorf("%s: skills are installed from a repository or directory, not a file", loc)
+		}
+		if _, err := os.Stat(filepath.Join(src, "SKILL.md")); err != nil {
+			return fmt.Errorf("%s: no SKILL.md found", loc)
+		}
+		_ = os.RemoveAll(filepath.Join(src, ".git"))
+	} else {
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s: %s are installed from a single file", loc, q.Kind.Name())
+		}
+		if info.Size() > maxFileBytes {
+			return fmt.Errorf("%s: larger than %d bytes", loc, maxFileBytes)
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		q.Provenance.SHA256 = hex.EncodeToString(sum[:])
+	}
+
+	dest := q.Path()
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(src, dest)
+}
+
+// checkout fetches ref (a branch, tag or commit; "" for the default
+// branch) of repo into dir with history depth 1, checks out path (""
+// for everything) and returns the commit. Blobs outside path are not
+// downloaded when the server supports partial clones.
+func checkout(ctx context.Context, repo, ref, path, dir string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	spec := path
+	if spec == "" {
+		spec = "."
+	}
+	steps := [][]string{
+		{"init", "--quiet", dir},
+		{"-C", dir, "fetch", "--quiet", "--depth=1", "--filter=blob:none", "--end-of-options", repo, ref},
+		{"-C", dir, "cat-file", "-e", "FETCH_HEAD:" + path},
+		{"-C", dir, "checkout", "--quiet", "FETCH_HEAD", "--", spec},
+	}
+	for _, args := range steps {
+		if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+			verb := args[0]
+			if verb == "-C" {
+				verb = args[2]
+			}
+			if verb == "cat-file" {
+				return "", fmt.Errorf("%s: %s not found at %s", repo, path, ref)
+			}
+			return "", fmt.Errorf("git %s %s: %v: %s", verb, repo, err, strings.TrimSpace(string(out)))
+		}
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "FETCH_HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Install moves the staged component into targetDir, replacing any
+// installed copy, records its provenance and removes the quarantine.
+func (q *Quarantine) Install(targetDir string) error {
+	src, dst := q.Path(), q.Kind.Path(targetDir, q.Name)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		// Quarantine and project may be on different filesystems
+		if err := copyTree(src, dst); err != nil {
+			return err
+		}
+	}
+
+	q.Provenance.InstalledAt = time.Now().UTC()
+	if err := catalog.WriteProvenance(targetDir, q.Kind.Name(), q.Name, q.Provenance); err != nil {
+		return err
+	}
+	q.Discard()
+	return nil
+}
+
+// Discard deletes the quarantine.
+func (q *Quarantine) Discard() {
+	_ = os.RemoveAll(q.Dir)
+}
+
+// removeSymlinks deletes every symbolic link under root.
+func removeSymlinks(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return os.Remove(p)
+		}
+		return nil
+	})
+}
+
+// copyTree copies a file or directory, keeping permissions.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil // symlinks and devices are not installed
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
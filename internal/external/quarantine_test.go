@@ -0,0 +1,291 @@
+package external
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setHome points the quarantine at a temporary directory.
+func setHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+}
+
+// gitRepo creates a repository holding repoFiles and a symlink, committed
+// on branch main, and returns its file:// URL and commit.
+func gitRepo(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	if err := os.MkdirAll(filepath.Join(dir, "skills", "sqlx"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(dir, "skills", "sqlx", "passwd")); err != nil {
+		t.Fatal(err)
+	}
+	return "file://" + dir, commitFiles(t, dir, repoFiles)
+}
+
+// commitFiles writes files (slash paths to contents) into the repository
+// at dir, commits them and returns the commit.
+func commitFiles(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "--quiet", "-m", "update")
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+var repoFiles = map[string]string{
+	"agents/dba.md":           "---\ndescription: Database admin\n---\nBody\n",
+	"skills/sqlx/SKILL.md":    "---\nname: sqlx\n---\nSkill\n",
+	"skills/sqlx/examples.md": "examples\n",
+	"README.md":               "# repo\n",
+}
+
+func TestFetchLocationGitFile(t *testing.T) {
+	setHome(t)
+	repo, commit := gitRepo(t)
+
+	q, err := FetchLocation(context.Background(), "agents", "dba", Location{Repo: repo, Ref: "main", Path: "agents/dba.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Discard()
+	data, err := os.ReadFile(q.Path())
+	if err != nil || string(data) != repoFiles["agents/dba.md"] {
+		t.Fatalf("staged %q, %v", data, err)
+	}
+	p := q.Provenance
+	if p.Repo != repo || p.Ref != "main" || p.Path != "agents/dba.md" || p.Commit != commit || p.SHA256 == "" {
+		t.Errorf("provenance = %+v", p)
+	}
+}
+
+func TestFetchLocationGitSkill(t *testing.T) {
+	setHome(t)
+	repo, commit := gitRepo(t)
+
+	q, err := FetchLocation(context.Background(), "skills", "sqlx", Location{Repo: repo, Path: "skills/sqlx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Discard()
+	if q.Provenance.Commit != commit {
+		t.Errorf("commit = %q, want %q", q.Provenance.Commit, commit)
+	}
+	files, err := q.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	// The symlink is not staged
+	if got := strings.Join(paths, ","); got != "skills/sqlx/SKILL.md,skills/sqlx/examples.md" {
+		t.Errorf("files = %s", got)
+	}
+}
+
+func TestFetchLocationGitErrors(t *testing.T) {
+	setHome(t)
+	repo, _ := gitRepo(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		kind, name string
+		loc        Location
+		want       string
+	}{
+		{"agents", "nope", Location{Repo: repo, Path: "agents/nope.md"}, "not found"},
+		{"agents", "dba", Location{Repo: repo, Ref: "missing", Path: "agents/dba.md"}, "git fetch"},
+		{"skills", "dba", Location{Repo: repo, Path: "agents/dba.md"}, "not a file"},
+		{"skills", "agents", Location{Repo: repo, Path: "agents"}, "no SKILL.md"},
+		{"agents", "sqlx", Location{Repo: repo, Path: "skills/sqlx"}, "single file"},
+		{"agents", "passwd", Location{Repo: repo, Path: "skills/sqlx/passwd"}, "not found"},
+	}
+	for _, tt := range tests {
+		_, err := FetchLocation(ctx, tt.kind, tt.name, tt.loc)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("FetchLocation(%s, %s) err = %v, want %q", tt.kind, tt.loc, err, tt.want)
+		}
+	}
+}
+
+func TestFetchLocationRejectsOptionRef(t *testing.T) {
+	setHome(t)
+	repo, _ := gitRepo(t)
+	marker := filepath.Join(t.TempDir(), "pwned")
+
+	// As replayed from a tampered provenance record
+	loc := Location{Repo: repo, Ref: "--upload-pack=touch " + marker, Path: "agents/dba.md"}
+	_, err := FetchLocation(context.Background(), "agents", "dba", loc)
+	if err == nil || !strings.Contains(err.Error(), "invalid ref") {
+		t.Errorf("err = %v, want invalid ref", err)
+	}
+
+	// A repository that looks like an option is passed after --end-of-options
+	loc = Location{Repo: "--upload-pack=touch " + marker, Path: "agents/dba.md"}
+	if _, err := FetchLocation(context.Background(), "agents", "dba", loc); err == nil {
+		t.Error("want an error for an option-like repository")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("git ran the injected upload-pack command")
+	}
+}
+
+// writeTarGz writes a gzipped tarball of files, all under prefix.
+func writeTarGz(t *testing.T, name, prefix string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for p, content := range files {
+		hdr := &tar.Header{Name: prefix + p, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := &tar.Header{Name: prefix + "skills/sqlx/passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}
+	if err := tw.WriteHeader(link); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeZip writes a zip archive of files.
+func writeZip(t *testing.T, name string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for p, content := range files {
+		w, err := zw.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetchLocationArchives(t *testing.T) {
+	setHome(t)
+	dir := t.TempDir()
+	tgz := filepath.Join(dir, "repo-main.tar.gz")
+	writeTarGz(t, tgz, "repo-main/", repoFiles)
+	zipFile := filepath.Join(dir, "repo.zip")
+	writeZip(t, zipFile, repoFiles)
+
+	for _, archive := range []string{"file://" + tgz, "file://" + zipFile} {
+		t.Run(filepath.Base(archive), func(t *testing.T) {
+			q, err := Fetch(context.Background(), "test", "skills", "sqlx", archive+"#skills/sqlx")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer q.Discard()
+			if q.Provenance.Archive != archive || q.Provenance.Path != "skills/sqlx" || q.Provenance.Commit != "" {
+				t.Errorf("provenance = %+v", q.Provenance)
+			}
+			files, err := q.Files()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 2 {
+				t.Errorf("files = %+v", files)
+			}
+
+			q, err = Fetch(context.Background(), "test", "agents", "dba", archive+"#agents/dba.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer q.Discard()
+			if data, err := os.ReadFile(q.Path()); err != nil || string(data) != repoFiles["agents/dba.md"] {
+				t.Errorf("staged %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestFetchLocationArchiveTraversal(t *testing.T) {
+	setHome(t)
+	dir := t.TempDir()
+	tgz := filepath.Join(dir, "evil.tar.gz")
+	writeTarGz(t, tgz, "", map[string]string{"../../evil.md": "x", "agents/dba.md": "y"})
+
+	_, err := FetchLocation(context.Background(), "agents", "dba", Location{Archive: "file://" + tgz, Path: "agents/dba.md"})
+	if err == nil || !strings.Contains(err.Error(), "unsafe entry") {
+		t.Errorf("err = %v, want unsafe entry", err)
+	}
+	// The archive is extracted into <quarantine>/<name>-*/.src
+	if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".bmad", "quarantine", "evil.md")); err == nil {
+		t.Error("entry written outside the extraction directory")
+	}
+}
+
+func TestArchiveTarget(t *testing.T) {
+	dir := filepath.FromSlash("/q/src")
+	tests := []struct {
+		name, want string
+	}{
+		{"repo/skills/a/SKILL.md", "/q/src/repo/skills/a/SKILL.md"},
+		{"/abs/file.md", "/q/src/abs/file.md"},
+		{"./a/./b.md", "/q/src/a/b.md"},
+	}
+	for _, tt := range tests {
+		got, err := archiveTarget(dir, tt.name)
+		if err != nil || got != filepath.FromSlash(tt.want) {
+			t.Errorf("archiveTarget(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	for _, name := range []string{"../evil.md", "repo/../../evil.md", `repo\..\..\evil.md`, ".."} {
+		if got, err := archiveTarget(dir, name); err == nil {
+			t.Errorf("archiveTarget(%q) = %q, want an error", name, got)
+		}
+	}
+}
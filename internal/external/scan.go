@@ -0,0 +1,135 @@
+package external
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severity ranks a finding.
+type Severity int
+
+const (
+	Warning Severity = iota // worth a look
+	High                    // likely dangerous
+)
+
+func (s Severity) String() string {
+	if s == High {
+		return "high"
+	}
+	return "warning"
+}
+
+// Finding is a risky pattern found in a quarantined component.
+type Finding struct {
+	File     string // File.Path
+	Line     int    // 1-based; 0 for file-level findings
+	Rule     string
+	Severity Severity
+	Text     string // the matching line, trimmed
+}
+
+func (f Finding) String() string {
+	loc := f.File
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("%s: %s", loc, f.Rule)
+}
+
+// riskRule matches risky content line by line.
+type riskRule struct {
+	name     string
+	severity Severity
+	pattern  *regexp.Regexp
+}
+
+var riskRules = []riskRule{
+	{"downloads and runs a script", High, regexp.MustCompile(`(?i)\b(curl|wget|iwr|invoke-webrequest)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|da|k)?sh\b|\b(ba|z)?sh\s+<\(\s*(curl|wget)|\biex\b.*\b(iwr|invoke-webrequest|downloadstring)`)},
+	{"evaluates downloaded code", High, regexp.MustCompile(`(?i)\beval\b[^\n]*\$\(\s*(curl|wget)|base64\s+(-d|--decode)[^|\n]*\|\s*(ba|z)?sh\b`)},
+	{"unrestricted Bash tool access", High, regexp.MustCompile(`(?i)^\s*allowed-tools\s*:.*\bBash\s*(\(\s*\*\s*\)|(,|\s|$))`)},
+	{"bypasses permission prompts", High, regexp.MustCompile(`(?i)--dangerously-skip-permissions|bypassPermissions`)},
+	{"private key", High, regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key", High, regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"hard-coded credential", High, regexp.MustCompile(`(?i)\b(api[_-]?key|secret|token|passw(or)?d)\b\s*[:=]\s*["']?[A-Za-z0-9_\-/+]{16,}`)},
+	{"reads credentials", Warning, regexp.MustCompile(`(~|\$HOME|\$\{HOME\})/\.(ssh|aws|gnupg|netrc|docker/config\.json|kube/config)|\bid_(rsa|ed25519)\b`)},
+	{"deletes files recursively", Warning, regexp.MustCompile(`\brm\s+-[a-zA-Z]*r[a-zA-Z]*f?\s+(/|~|\$HOME|\*)`)},
+	{"runs with sudo", Warning, regexp.MustCompile(`\bsudo\s+\S`)},
+	{"terminal escape sequence", High, regexp.MustCompile(`\x1b`)},
+	{"sends data to a remote host", Warning, regexp.MustCompile(`(?i)\bcurl\b[^\n]*(-d|--data|-F|--form|-T|--upload-file)\b`)},
+}
+
+// Scan checks the staged files for risky content. Executable, binary and
+// symlinked files are reported too.
+func (q *Quarantine) Scan() ([]Finding, error) {
+	files, err := q.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, f := range files {
+		switch {
+		case f.Symlink:
+			findings = append(findings, Finding{File: f.Path, Rule: "symbolic link", Severity: High})
+			continue
+		case f.Binary:
+			findings = append(findings, Finding{File: f.Path, Rule: "binary file", Severity: Warning})
+			continue
+		case f.Executable:
+			findings = append(findings, Finding{File: f.Path, Rule: "executable file", Severity: Warning})
+		}
+
+		data, err := q.ReadFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, scanContent(f.Path, data)...)
+	}
+	return findings, nil
+}
+
+func scanContent(path string, data []byte) []Finding {
+	var findings []Finding
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		for _, r := range riskRules {
+			if r.pattern.MatchString(line) {
+				findings = append(findings, Finding{
+					File:     path,
+					Line:     n,
+					Rule:     r.name,
+					Severity: r.severity,
+					Text:     truncate(strings.TrimSpace(line), 120),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// HasHigh reports whether any finding is high severity.
+func HasHigh(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == High {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
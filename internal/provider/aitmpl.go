@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// Defaults for the aitmpl.com catalog.
+const (
+	DefaultAitmplURL  = "https://www.aitmpl.com/components.json"
+	DefaultAitmplRepo = "https://github.com/davila7/claude-code-templates"
+	aitmplRef         = "main"
+	aitmplComponents  = "cli-tool/components"
+)
+
+// Aitmpl lists the components published in aitmpl.com's components.json.
+type Aitmpl struct {
+	name   string
+	URL    string // components.json location; "" uses DefaultAitmplURL
+	Repo   string // repository the components live in; "" uses DefaultAitmplRepo
+	Client *http.Client
+}
+
+func (a *Aitmpl) Name() string { return a.name }
+
+// aitmplComponent is one item of components.json, which maps plural
+// component types ("agents", "commands", ...) to lists of these.
+type aitmplComponent struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+}
+
+func (a *Aitmpl) Fetch(ctx context.Context) ([]Entry, error) {
+	entries, _, _, err := fetchDocument(ctx, a, Validators{})
+	return entries, err
+}
+
+func (a *Aitmpl) location() string {
+	if a.URL == "" {
+		return DefaultAitmplURL
+	}
+	return a.URL
+}
+
+func (a *Aitmpl) httpClient() *http.Client { return a.Client }
+
+func (a *Aitmpl) parse(data []byte) ([]Entry, error) {
+	var index map[string]json.RawMessage
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	repo := a.Repo
+	if repo == "" {
+		repo = DefaultAitmplRepo
+	}
+
+	types := make([]string, 0, len(index))
+	for typ := range index {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	var entries []Entry
+	for _, typ := range types {
+		k, ok := catalog.LookupKind(typ)
+		if !ok {
+			continue // mcps, settings, hooks, templates
+		}
+		var comps []aitmplComponent
+		if err := json.Unmarshal(index[typ], &comps); err != nil {
+			continue
+		}
+		for _, c := range comps {
+			entries = append(entries, Entry{
+				Type:        k.Name(),
+				Name:        c.Name,
+				Description: aitmplDescription(c),
+				URL:         aitmplURL(repo, k.Name(), c.Path),
+				Ref:         aitmplRef,
+				Path:        c.Path,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// aitmplURL links to the component in the repository: a directory for
+// skills, a markdown file for everything else.
+func aitmplURL(repo, kind, p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if kind == "skills" {
+		return fmt.Sprintf("%s/tree/%s/%s/%s/%s", repo, aitmplRef, aitmplComponents, kind, strings.TrimSuffix(p, "/SKILL.md"))
+	}
+	if !strings.HasSuffix(p, ".md") {
+		p += ".md"
+	}
+	return fmt.Sprintf("%s/blob/%s/%s/%s/%s", repo, aitmplRef, aitmplComponents, kind, p)
+}
+
+// aitmplDescription falls back to the frontmatter description embedded in
+// the component's content.
+func aitmplDescription(c aitmplComponent) string {
+	if c.Description != "" {
+		return c.Description
+	}
+	front, _, ok := catalog.SplitFrontmatter([]byte(c.Content))
+	if !ok {
+		return ""
+	}
+	var meta struct {
+		Description string `yaml:"description"`
+	}
+	_ = yaml.Unmarshal(front, &meta)
+	return meta.Description
+}
@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/config"
+)
+
+// DefaultTTL is how long a cached catalog is used without revalidation.
+const DefaultTTL = 24 * time.Hour
+
+// EnvCacheTTL overrides DefaultTTL with a Go duration, e.g. "6h".
+const EnvCacheTTL = "BMAD_CATALOG_TTL"
+
+// Cache stores fetched catalogs as one JSON file per provider.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// NewCache returns the cache under ~/.bmad/cache/catalogs/, with the TTL
+// from $BMAD_CATALOG_TTL when set.
+func NewCache() (*Cache, error) {
+	c := &Cache{Dir: config.CatalogCacheDir(), TTL: DefaultTTL}
+	if v := os.Getenv(EnvCacheTTL); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvCacheTTL, err)
+		}
+		c.TTL = ttl
+	}
+	return c, nil
+}
+
+// Record is a cached catalog.
+type Record struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	URL        string     `json:"url,omitempty"`
+	Ref        string     `json:"ref,omitempty"`
+	Dir        string     `json:"dir,omitempty"`
+	FetchedAt  time.Time  `json:"fetched_at"`
+	Validators Validators `json:"validators"`
+	Entries    []Entry    `json:"entries"`
+	Size       int64      `json:"-"` // file size, set when loaded
+}
+
+// Age is the time since the catalog was last fetched or revalidated.
+func (r *Record) Age() time.Duration { return time.Since(r.FetchedAt) }
+
+// matches reports whether the record was fetched with configuration c.
+func (r *Record) matches(c Config) bool {
+	return r.Type == c.Type && r.URL == c.URL && r.Ref == c.Ref && r.Dir == c.Dir
+}
+
+// Fresh reports whether r is younger than the cache TTL.
+func (c *Cache) Fresh(r *Record) bool { return r.Age() < c.TTL }
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (c *Cache) path(name string) string {
+	return filepath.Join(c.Dir, unsafeFileChars.ReplaceAllString(name, "_")+".json")
+}
+
+// Load returns the cached catalog for name, or fs.ErrNotExist.
+func (c *Cache) Load(name string) (*Record, error) {
+	data, err := os.ReadFile(c.path(name))
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("cached catalog %s: %w", name, err)
+	}
+	r.Size = int64(len(data))
+	for i := range r.Entries {
+		r.Entries[i].Source = r.Name
+	}
+	return &r, nil
+}
+
+// Save writes r to the cache.
+func (c *Cache) Save(r *Record) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path(r.Name) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path(r.Name))
+}
+
+// List returns all cached catalogs sorted by name. Unreadable files are
+// skipped.
+func (c *Cache) List() ([]*Record, error) {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var records []*Record
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var r Record
+		if json.Unmarshal(data, &r) != nil {
+			continue
+		}
+		r.Size = int64(len(data))
+		records = append(records, &r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, nil
+}
+
+// Clear removes the named catalogs, or all of them when none are named,
+// and returns the names removed.
+func (c *Cache) Clear(names ...string) ([]string, error) {
+	if len(names) == 0 {
+		records, err := c.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			names = append(names, r.Name)
+		}
+	}
+	var removed []string
+	for _, name := range names {
+		err := os.Remove(c.path(name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
+
+// Cached fetches a provider's catalog through the cache.
+type Cached struct {
+	Provider CatalogProvider
+	Config   Config
+	Cache    *Cache
+}
+
+// NewCached returns the provider described by c, cached in cache.
+func NewCached(c Config, cache *Cache) (*Cached, error) {
+	p, err := New(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Cached{Provider: p, Config: c, Cache: cache}, nil
+}
+
+func (c *Cached) Name() string { return c.Provider.Name() }
+
+func (c *Cached) Fetch(ctx context.Context) ([]Entry, error) {
+	entries, _, err := c.Load(ctx, false)
+	return entries, err
+}
+
+// Status tells where a catalog came from.
+type Status struct {
+	FromCache   bool          // entries were read from the cache
+	NotModified bool          // the server confirmed the cached copy is current
+	Age         time.Duration // age of the cached copy, when FromCache
+	FetchErr    error         // why a stale cached copy was used instead
+}
+
+// Load returns the catalog, from the cache while it is fresh (unless
+// refresh is set) and from the provider otherwise. Document catalogs with
+// cache validators are revalidated with a conditional request. When the
+// fetch fails and a cached copy exists, that copy is returned with the
+// error in Status.FetchErr.
+func (c *Cached) Load(ctx context.Context, refresh bool) ([]Entry, Status, error) {
+	name := c.Name()
+	cached, err := c.Cache.Load(name)
+	if err != nil || !cached.matches(c.Config) {
+		cached = nil
+	}
+	if cached != nil && !refresh && c.Cache.Fresh(cached) {
+		return cached.Entries, Status{FromCache: true, Age: cached.Age()}, nil
+	}
+
+	var entries []Entry
+	var validators Validators
+	notModified := false
+	if doc, ok := c.Provider.(documentProvider); ok {
+		var v Validators
+		if cached != nil {
+			v = cached.Validators
+		}
+		entries, validators, notModified, err = fetchDocument(ctx, doc, v)
+	} else {
+		entries, err = c.Provider.Fetch(ctx)
+	}
+
+	if err != nil {
+		if cached != nil {
+			return cached.Entries, Status{FromCache: true, Age: cached.Age(), FetchErr: err}, nil
+		}
+		return nil, Status{}, err
+	}
+	if notModified {
+		cached.FetchedAt = time.Now()
+		_ = c.Cache.Save(cached)
+		return cached.Entries, Status{FromCache: true, NotModified: true}, nil
+	}
+
+	_ = c.Cache.Save(&Record{
+		Name:       name,
+		Type:       c.Config.Type,
+		URL:        c.Config.URL,
+		Ref:        c.Config.Ref,
+		Dir:        c.Config.Dir,
+		FetchedAt:  time.Now(),
+		Validators: validators,
+		Entries:    entries,
+	})
+	return entries, Status{}, nil
+}
+
+// FormatAge renders a duration as a short human age, e.g. "3h ago".
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+)
+
+// maxCatalogBytes caps how much of a remote catalog is read.
+const maxCatalogBytes = 16 << 20
+
+// defaultClient is used by providers without their own client.
+var defaultClient = &http.Client{Timeout: 15 * time.Second}
+
+// Validators are the HTTP cache validators of a fetched document.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// documentProvider is a provider backed by a single document, which lets
+// the cache revalidate it with a conditional request.
+type documentProvider interface {
+	CatalogProvider
+	location() string
+	httpClient() *http.Client
+	parse(data []byte) ([]Entry, error)
+}
+
+// fetchDocument fetches and parses p's document. When v is set and the
+// server answers 304 Not Modified, notModified is true and no entries are
+// returned.
+func fetchDocument(ctx context.Context, p documentProvider, v Validators) (entries []Entry, next Validators, notModified bool, err error) {
+	data, next, notModified, err := fetch(ctx, p.httpClient(), p.location(), v)
+	if err != nil || notModified {
+		return nil, next, notModified, err
+	}
+	entries, err = p.parse(data)
+	if err != nil {
+		return nil, next, false, fmt.Errorf("parsing %s: %w", p.location(), err)
+	}
+	return normalizeEntries(p.Name(), entries), next, false, nil
+}
+
+// fetch reads location, which is an http(s) URL, a file:// URL or a local
+// path. HTTP requests are conditional on v when it is set.
+func fetch(ctx context.Context, client *http.Client, location string, v Validators) ([]byte, Validators, bool, error) {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		if err == nil && u.Scheme == "file" {
+			location = u.Path
+		}
+		data, err := os.ReadFile(location)
+		return data, Validators{}, false, err
+	}
+
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, Validators{}, false, err
+	}
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, Validators{}, false, fmt.Errorf("GET %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && v != (Validators{}) {
+		return nil, v, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, Validators{}, false, fmt.Errorf("GET %s: status %d", location, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCatalogBytes))
+	if err != nil {
+		return nil, Validators{}, false, fmt.Errorf("reading %s: %w", location, err)
+	}
+	next := Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	return body, next, false, nil
+}
+
+// urlRef returns the ref in a component URL, or "".
+func urlRef(rawURL string) string {
+	loc, err := external.Resolve(rawURL)
+	if err != nil {
+		return ""
+	}
+	return loc.Ref
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+)
+
+// GitRepo lists the components of a git repository laid out like a
+// template directory (agents/, skills/, ...), at the repository root or
+// under .claude/.
+type GitRepo struct {
+	name string
+	URL  string // anything git clone accepts, including file:// URLs
+	Ref  string // branch or tag; "" uses the default branch
+	Dir  string // template directory inside the repo; "" tries the root, then .claude
+}
+
+func (g *GitRepo) Name() string { return g.name }
+
+func (g *GitRepo) Fetch(ctx context.Context) ([]Entry, error) {
+	// The URL and ref come from the user's config; neither may pass as an option
+	if err := external.CheckRef(g.Ref); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "ck-catalog-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	args := []string{"clone", "--depth=1", "--quiet"}
+	if g.Ref != "" {
+		args = append(args, "--branch", g.Ref)
+	}
+	args = append(args, "--", g.URL, tmp)
+	if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git clone %s: %v: %s", g.URL, err, strings.TrimSpace(string(out)))
+	}
+
+	ref := g.Ref
+	if ref == "" {
+		out, err := exec.CommandContext(ctx, "git", "-C", tmp, "rev-parse", "HEAD").Output()
+		if err != nil {
+			return nil, fmt.Errorf("git rev-parse: %w", err)
+		}
+		ref = strings.TrimSpace(string(out))
+	}
+
+	dirs := []string{g.Dir}
+	if g.Dir == "" {
+		dirs = []string{".", ".claude"}
+	}
+	var entries []Entry
+	for _, dir := range dirs {
+		categories, err := catalog.ScanTemplate(filepath.Join(tmp, dir))
+		if err != nil {
+			continue
+		}
+		for _, cat := range categories {
+			for _, c := range cat.Components {
+				rel, err := filepath.Rel(tmp, c.Path)
+				if err != nil {
+					continue
+				}
+				rel = filepath.ToSlash(rel)
+				entries = append(entries, Entry{
+					Type:        c.Type,
+					Name:        c.Name,
+					Description: c.Description,
+					URL:         g.componentURL(c.Type, ref, rel),
+					Ref:         ref,
+					Path:        rel,
+				})
+			}
+		}
+		if len(entries) > 0 {
+			break
+		}
+	}
+	return normalizeEntries(g.name, entries), nil
+}
+
+// componentURL links to a component: a tree or blob URL for GitHub
+// repositories, a git URL with "@<ref>#<path>" elsewhere (see
+// external.Resolve).
+func (g *GitRepo) componentURL(kind, ref, rel string) string {
+	repo := strings.TrimRight(g.URL, "/")
+	u, err := url.Parse(repo)
+	if err != nil {
+		return g.URL
+	}
+	switch {
+	case u.Host == "github.com":
+		repo = strings.TrimSuffix(repo, ".git")
+		if kind == "skills" {
+			return fmt.Sprintf("%s/tree/%s/%s", repo, ref, rel)
+		}
+		return fmt.Sprintf("%s/blob/%s/%s", repo, ref, rel)
+	case u.Scheme == "http" || u.Scheme == "https":
+		repo = "git+" + repo
+	case u.Scheme == "":
+		abs, err := filepath.Abs(repo)
+		if err != nil {
+			return g.URL
+		}
+		repo = "file://" + filepath.ToSlash(abs)
+	}
+	return fmt.Sprintf("%s@%s#%s", repo, ref, rel)
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// JSONIndex reads a catalog in the generic index format, either a bare
+// array of entries or an object with an "entries" array:
+//
+//	{"entries": [
+//	  {"type": "skills", "name": "sql-review", "description": "...",
+//	   "url": "https://github.com/org/sql-review", "ref": "v1.2.0"}
+//	]}
+type JSONIndex struct {
+	name   string
+	URL    string // http(s) URL, file:// URL or local path
+	Client *http.Client
+}
+
+func (j *JSONIndex) Name() string { return j.name }
+
+func (j *JSONIndex) Fetch(ctx context.Context) ([]Entry, error) {
+	entries, _, _, err := fetchDocument(ctx, j, Validators{})
+	return entries, err
+}
+
+func (j *JSONIndex) location() string { return j.URL }
+
+func (j *JSONIndex) httpClient() *http.Client { return j.Client }
+
+func (j *JSONIndex) parse(data []byte) ([]Entry, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var entries []Entry
+		err := json.Unmarshal(data, &entries)
+		return entries, err
+	}
+	var index struct {
+		Entries []Entry `json:"entries"`
+	}
+	err := json.Unmarshal(data, &index)
+	return index.Entries, err
+}
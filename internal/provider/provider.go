@@ -0,0 +1,179 @@
+// Package provider fetches external component catalogs for smart add.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// Entry is a component offered by an external catalog.
+type Entry struct {
+	Source      string `json:"-"`              // provider name
+	Type        string `json:"type"`           // a registered catalog kind, e.g. "skills"
+	Name        string `json:"name"`           // component name
+	Description string `json:"description"`    // what it does
+	URL         string `json:"url"`            // where to install it from
+	Ref         string `json:"ref,omitempty"`  // git branch, tag or commit, if known
+	Path        string `json:"path,omitempty"` // path inside the repository, if known
+}
+
+// CatalogProvider lists the components of one external catalog.
+type CatalogProvider interface {
+	// Name identifies the provider; it becomes the recommendation source.
+	Name() string
+	// Fetch returns the catalog's entries.
+	Fetch(ctx context.Context) ([]Entry, error)
+}
+
+// Provider types accepted in a providers file and by ParseFlag.
+const (
+	TypeVoltAgent = "voltagent"
+	TypeAitmpl    = "aitmpl"
+	TypeGit       = "git"
+	TypeJSON      = "json"
+)
+
+// EnvProvidersFile names a YAML file listing the providers to use instead
+// of the defaults.
+const EnvProvidersFile = "BMAD_CATALOG_PROVIDERS"
+
+// Config describes one provider, as listed in a providers file:
+//
+//	providers:
+//	  - type: voltagent
+//	  - name: team
+//	    type: git
+//	    url: https://git.example.com/team/claude-templates.git
+//	    ref: main
+//	  - type: json
+//	    url: https://example.com/claude-index.json
+type Config struct {
+	Name string `yaml:"name"` // defaults to one derived from type and URL
+	Type string `yaml:"type"`
+	URL  string `yaml:"url"` // optional for voltagent and aitmpl
+	Ref  string `yaml:"ref"` // git only
+	Dir  string `yaml:"dir"` // git only: template directory inside the repo
+}
+
+type providersFile struct {
+	Providers []Config `yaml:"providers"`
+}
+
+// DefaultConfigs are used when no providers file is configured.
+func DefaultConfigs() []Config {
+	return []Config{{Type: TypeVoltAgent}, {Type: TypeAitmpl}}
+}
+
+// Configs returns the providers from the file named by
+// $BMAD_CATALOG_PROVIDERS, or the defaults when it is unset.
+func Configs() ([]Config, error) {
+	file := os.Getenv(EnvProvidersFile)
+	if file == "" {
+		return DefaultConfigs(), nil
+	}
+	return LoadFile(file)
+}
+
+// LoadFile reads a providers file.
+func LoadFile(file string) ([]Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var pf providersFile
+	if err := yaml.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for i, c := range pf.Providers {
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s: provider %d: %w", file, i+1, err)
+		}
+	}
+	return pf.Providers, nil
+}
+
+// ParseFlag parses a --catalog value of the form "type=location", e.g.
+// "git=https://example.com/repo.git" or "json=./index.json".
+func ParseFlag(value string) (Config, error) {
+	typ, loc, _ := strings.Cut(value, "=")
+	c := Config{Type: typ, URL: loc}
+	if err := c.validate(); err != nil {
+		return Config{}, fmt.Errorf("--catalog %q: %w", value, err)
+	}
+	return c, nil
+}
+
+func (c Config) validate() error {
+	switch c.Type {
+	case TypeVoltAgent, TypeAitmpl:
+		return nil
+	case TypeGit, TypeJSON:
+		if c.URL == "" {
+			return fmt.Errorf("%s provider needs a url", c.Type)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown provider type %q (valid: %s, %s, %s, %s)", c.Type, TypeVoltAgent, TypeAitmpl, TypeGit, TypeJSON)
+}
+
+// name returns the configured name, or one derived from the location:
+// the repository name for git, the file name for JSON indexes.
+func (c Config) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	switch c.Type {
+	case TypeGit, TypeJSON:
+		p := c.URL
+		if u, err := url.Parse(c.URL); err == nil && u.Path != "" {
+			p = u.Path
+		}
+		base := path.Base(strings.TrimRight(strings.ReplaceAll(p, `\`, "/"), "/"))
+		base = strings.TrimSuffix(strings.TrimSuffix(base, ".git"), ".json")
+		if base != "" && base != "." && base != "/" {
+			return base
+		}
+	}
+	return c.Type
+}
+
+// New returns the provider described by c.
+func New(c Config) (CatalogProvider, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case TypeVoltAgent:
+		return &VoltAgent{name: c.name(), URL: c.URL}, nil
+	case TypeAitmpl:
+		return &Aitmpl{name: c.name(), URL: c.URL}, nil
+	case TypeGit:
+		return &GitRepo{name: c.name(), URL: c.URL, Ref: c.Ref, Dir: c.Dir}, nil
+	default:
+		return &JSONIndex{name: c.name(), URL: c.URL}, nil
+	}
+}
+
+// normalizeEntries sets the source, maps types to registered kind names and
+// drops entries without a known type or a name.
+func normalizeEntries(source string, entries []Entry) []Entry {
+	out := entries[:0]
+	for _, e := range entries {
+		k, ok := catalog.LookupKind(e.Type)
+		if !ok || strings.TrimSpace(e.Name) == "" {
+			continue
+		}
+		e.Source, e.Type = source, k.Name()
+		e.Description = strings.TrimSpace(e.Description)
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,333 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serve answers every request with body.
+func serve(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const voltagentReadme = `# Awesome Agent Skills
+
+A curated list. See [the docs](https://example.com/docs) first.
+
+## Documents
+
+- **[anthropics/pdf](https://github.com/anthropics/skills/tree/main/document-skills/pdf)** - Extract text and tables from PDFs
+- [org/xlsx](https://github.com/org/skills/tree/v2/xlsx) – Spreadsheets
+* [solo](https://gitlab.com/team/solo) : A single skill repository
+- [anthropics/pdf](https://github.com/anthropics/skills/tree/main/document-skills/pdf) - Duplicate link
+
+| Not | A list |
+`
+
+func TestVoltAgentReadme(t *testing.T) {
+	srv := serve(t, voltagentReadme)
+	v := &VoltAgent{name: "voltagent", URL: srv.URL + "/README.md"}
+	entries, err := v.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Source: "voltagent", Type: "skills", Name: "pdf", Description: "Extract text and tables from PDFs", URL: "https://github.com/anthropics/skills/tree/main/document-skills/pdf", Ref: "main"},
+		{Source: "voltagent", Type: "skills", Name: "xlsx", Description: "Spreadsheets", URL: "https://github.com/org/skills/tree/v2/xlsx", Ref: "v2"},
+		{Source: "voltagent", Type: "skills", Name: "solo", Description: "A single skill repository", URL: "https://gitlab.com/team/solo"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestJSONIndex(t *testing.T) {
+	entry := `{"type": "skill", "name": "sql-review", "description": " Reviews SQL ", "url": "https://github.com/org/sql-review", "ref": "v1.2.0"}`
+	unknown := `{"type": "widgets", "name": "x", "url": "https://example.com/x"}`
+	for name, body := range map[string]string{
+		"array":  "[" + entry + ", " + unknown + "]",
+		"object": `{"entries": [` + entry + ", " + unknown + "]}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := serve(t, body)
+			j := &JSONIndex{name: "index", URL: srv.URL + "/index.json"}
+			entries, err := j.Fetch(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Entry{Source: "index", Type: "skills", Name: "sql-review", Description: "Reviews SQL", URL: "https://github.com/org/sql-review", Ref: "v1.2.0"}
+			if len(entries) != 1 || entries[0] != want {
+				t.Errorf("entries = %+v, want [%+v]", entries, want)
+			}
+		})
+	}
+}
+
+func TestJSONIndexInvalid(t *testing.T) {
+	srv := serve(t, `{"entries": 3}`)
+	j := &JSONIndex{name: "index", URL: srv.URL}
+	if _, err := j.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "parsing") {
+		t.Errorf("err = %v, want a parse error", err)
+	}
+}
+
+const aitmplComponentsJSON = `{
+  "agents": [
+    {"name": "frontend-developer", "path": "development-team/frontend-developer.md", "description": "Builds UIs"},
+    {"name": "dba", "path": "/database/dba", "content": "---\nname: dba\ndescription: From the content\n---\nBody"}
+  ],
+  "skills": [
+    {"name": "pdf", "path": "document/pdf/SKILL.md", "description": "PDFs"}
+  ],
+  "mcps": [
+    {"name": "github", "path": "github.json"}
+  ]
+}`
+
+func TestAitmplComponents(t *testing.T) {
+	srv := serve(t, aitmplComponentsJSON)
+	a := &Aitmpl{name: "aitmpl", URL: srv.URL + "/components.json", Repo: "https://github.com/org/templates"}
+	entries, err := a.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := "https://github.com/org/templates"
+	want := []Entry{
+		{Source: "aitmpl", Type: "agents", Name: "frontend-developer", Description: "Builds UIs", URL: base + "/blob/main/cli-tool/components/agents/development-team/frontend-developer.md", Ref: "main", Path: "development-team/frontend-developer.md"},
+		{Source: "aitmpl", Type: "agents", Name: "dba", Description: "From the content", URL: base + "/blob/main/cli-tool/components/agents/database/dba.md", Ref: "main", Path: "/database/dba"},
+		{Source: "aitmpl", Type: "skills", Name: "pdf", Description: "PDFs", URL: base + "/tree/main/cli-tool/components/skills/document/pdf", Ref: "main", Path: "document/pdf/SKILL.md"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+// templateRepo commits a template layout (slash paths under .claude/ to
+// contents) to a new repository and returns a GitRepo provider for it and
+// the commit.
+func templateRepo(t *testing.T, components map[string]string) (*GitRepo, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range components {
+		p := filepath.Join(dir, ".claude", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "init")
+	return &GitRepo{name: "team", URL: "file://" + dir}, git("rev-parse", "HEAD")
+}
+
+func TestGitRepoFetch(t *testing.T) {
+	g, commit := templateRepo(t, map[string]string{
+		"agents/dba.md":           "---\ndescription: Database admin\n---\n",
+		"skills/sqlx/SKILL.md":    "---\nname: sqlx\ndescription: SQL helpers\n---\n",
+		"skills/sqlx/examples.md": "examples",
+	})
+
+	entries, err := g.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := g.URL + "@" + commit + "#"
+	want := map[string]Entry{
+		"dba":  {Source: "team", Type: "agents", Name: "dba", Description: "Database admin", URL: repo + ".claude/agents/dba.md", Ref: commit, Path: ".claude/agents/dba.md"},
+		"sqlx": {Source: "team", Type: "skills", Name: "sqlx", Description: "SQL helpers", URL: repo + ".claude/skills/sqlx", Ref: commit, Path: ".claude/skills/sqlx"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for _, e := range entries {
+		if e != want[e.Name] {
+			t.Errorf("entry = %+v, want %+v", e, want[e.Name])
+		}
+	}
+
+	g.Ref = "main"
+	if entries, err := g.Fetch(context.Background()); err != nil || len(entries) != 2 || entries[0].Ref != "main" {
+		t.Errorf("Fetch(main) = %+v, %v", entries, err)
+	}
+}
+
+func TestGitRepoFetchRejectsOptions(t *testing.T) {
+	g, _ := templateRepo(t, map[string]string{"agents/dba.md": "dba"})
+	marker := filepath.Join(t.TempDir(), "pwned")
+
+	g.Ref = "--upload-pack=touch " + marker
+	if _, err := g.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid ref") {
+		t.Errorf("err = %v, want invalid ref", err)
+	}
+	// A URL that looks like an option is passed after "--"
+	g.Ref, g.URL = "", "--upload-pack=touch "+marker
+	if _, err := g.Fetch(context.Background()); err == nil {
+		t.Error("want an error for an option-like URL")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("git ran the injected upload-pack command")
+	}
+}
+
+// etagServer serves an index with ETag "v1", answering 304 to requests
+// that already have it, or 500 once failing is set.
+type etagServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	failing  atomic.Bool
+}
+
+func newETagServer(t *testing.T) *etagServer {
+	t.Helper()
+	s := &etagServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		switch {
+		case s.failing.Load():
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Header.Get("If-None-Match") == `"v1"`:
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`[{"type": "agents", "name": "dba", "url": "https://github.com/org/repo/blob/main/dba.md"}]`))
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestCached(t *testing.T, srv *etagServer, ttl time.Duration) *Cached {
+	t.Helper()
+	c, err := NewCached(Config{Name: "index", Type: TypeJSON, URL: srv.URL + "/index.json"}, &Cache{Dir: t.TempDir(), TTL: ttl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCachedLoadFresh(t *testing.T) {
+	srv := newETagServer(t)
+	c := newTestCached(t, srv, time.Hour)
+	ctx := context.Background()
+
+	entries, status, err := c.Load(ctx, false)
+	if err != nil || status.FromCache || len(entries) != 1 {
+		t.Fatalf("first load: %+v, %+v, %v", entries, status, err)
+	}
+	entries, status, err = c.Load(ctx, false)
+	if err != nil || !status.FromCache || len(entries) != 1 || entries[0].Source != "index" {
+		t.Fatalf("second load: %+v, %+v, %v", entries, status, err)
+	}
+	if n := srv.requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1 while the cache is fresh", n)
+	}
+
+	if _, _, err := c.Load(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2 after a refresh", n)
+	}
+}
+
+func TestCachedLoadNotModified(t *testing.T) {
+	srv := newETagServer(t)
+	c := newTestCached(t, srv, time.Hour)
+	ctx := context.Background()
+
+	if _, _, err := c.Load(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	// Age the cached copy past the TTL
+	r, err := c.Cache.Load("index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Validators.ETag != `"v1"` {
+		t.Fatalf("validators = %+v", r.Validators)
+	}
+	r.FetchedAt = time.Now().Add(-48 * time.Hour)
+	if err := c.Cache.Save(r); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, status, err := c.Load(ctx, false)
+	if err != nil || !status.NotModified || len(entries) != 1 {
+		t.Fatalf("load: %+v, %+v, %v", entries, status, err)
+	}
+	if n := srv.requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+	r, err = c.Cache.Load("index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Age() > time.Minute {
+		t.Errorf("FetchedAt = %v, want refreshed by the 304", r.FetchedAt)
+	}
+}
+
+func TestCachedLoadFetchErrorUsesCache(t *testing.T) {
+	srv := newETagServer(t)
+	c := newTestCached(t, srv, 0)
+	ctx := context.Background()
+
+	if _, _, err := c.Load(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	srv.failing.Store(true)
+
+	entries, status, err := c.Load(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.FromCache || status.FetchErr == nil || !strings.Contains(status.FetchErr.Error(), "status 500") {
+		t.Errorf("status = %+v, want the cached copy with the fetch error", status)
+	}
+	if len(entries) != 1 || entries[0].Name != "dba" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestCachedLoadFetchErrorWithoutCache(t *testing.T) {
+	srv := newETagServer(t)
+	srv.failing.Store(true)
+	c := newTestCached(t, srv, time.Hour)
+	if _, _, err := c.Load(context.Background(), false); err == nil {
+		t.Error("want an error with nothing cached")
+	}
+}
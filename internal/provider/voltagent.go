@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultVoltAgentURL is the awesome-agent-skills README.
+const DefaultVoltAgentURL = "https://raw.githubusercontent.com/VoltAgent/awesome-agent-skills/main/README.md"
+
+// VoltAgent lists the skills linked from the VoltAgent awesome list.
+type VoltAgent struct {
+	name   string
+	URL    string // README location; "" uses DefaultVoltAgentURL
+	Client *http.Client
+}
+
+func (v *VoltAgent) Name() string { return v.name }
+
+// listLink matches a markdown list item starting with a link, optionally in
+// bold, followed by a separator and a description:
+//
+//   - **[org/skill](https://github.com/org/repo/tree/main/skill)** - Does things
+var listLink = regexp.MustCompile(`^\s*[-*]\s+\**\[([^\]]+)\]\((https?://[^)\s]+)\)\**\s*(?:[-–—:|]\s*(.*))?$`)
+
+func (v *VoltAgent) Fetch(ctx context.Context) ([]Entry, error) {
+	entries, _, _, err := fetchDocument(ctx, v, Validators{})
+	return entries, err
+}
+
+func (v *VoltAgent) location() string {
+	if v.URL == "" {
+		return DefaultVoltAgentURL
+	}
+	return v.URL
+}
+
+func (v *VoltAgent) httpClient() *http.Client { return v.Client }
+
+func (v *VoltAgent) parse(data []byte) ([]Entry, error) {
+	return parseAwesomeList(string(data)), nil
+}
+
+// parseAwesomeList extracts skill entries from an awesome-list README.
+func parseAwesomeList(readme string) []Entry {
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(readme, "\n") {
+		m := listLink.FindStringSubmatch(line)
+		if m == nil || seen[m[2]] {
+			continue
+		}
+		seen[m[2]] = true
+		entries = append(entries, Entry{
+			Type:        "skills",
+			Name:        path.Base(strings.TrimSpace(m[1])),
+			Description: m[3],
+			URL:         m[2],
+			Ref:         urlRef(m[2]),
+		})
+	}
+	return entries
+}
@@ -0,0 +1,106 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/external"
+)
+
+// CheckExternal verifies that every non-local recommendation has a URL of
+// the shape its installer expects and that the URL is reachable. Failing
+// recommendations are dropped with a note; local ones pass through.
+func CheckExternal(ctx context.Context, client *http.Client, recs []Recommendation) ([]Recommendation, []Note) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	errs := make([]error, len(recs))
+	var wg sync.WaitGroup
+	for i, rec := range recs {
+		if rec.Source == "local" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			target, err := checkShape(rec)
+			if err == nil {
+				err = checkReachable(ctx, client, target)
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	var kept []Recommendation
+	var notes []Note
+	for i, rec := range recs {
+		if errs[i] != nil {
+			notes = append(notes, Note{Rec: rec, Message: errs[i].Error(), Dropped: true})
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	return kept, notes
+}
+
+// checkShape checks that the installer can resolve the URL to a
+// repository path or archive and returns the URL to probe. Skills are
+// directories, so they need a repository, directory or archive URL; other
+// kinds are single files, so they need a markdown file URL.
+func checkShape(rec Recommendation) (string, error) {
+	loc, err := external.Resolve(rec.URL)
+	if err != nil {
+		return "", err
+	}
+	isFile := strings.HasSuffix(loc.Path, ".md")
+	if rec.Type == "skills" && isFile {
+		return "", fmt.Errorf("skills need a repository, directory or archive URL, got %s", rec.URL)
+	}
+	if rec.Type != "skills" && !isFile {
+		return "", fmt.Errorf("%s must link to a markdown file, got %s", rec.Type, rec.URL)
+	}
+	if loc.Archive != "" {
+		return loc.Archive, nil
+	}
+	return rec.URL, nil
+}
+
+// checkReachable sends a HEAD request, falling back to GET for servers
+// that refuse HEAD, and accepts any non-error status. Non-HTTP URLs (git
+// remotes, local repositories) are not probed.
+func checkReachable(ctx context.Context, client *http.Client, target string) error {
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	status, err := probe(ctx, client, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+		status, err = probe(ctx, client, http.MethodGet, target)
+	}
+	if err != nil {
+		return fmt.Errorf("unreachable: %w", err)
+	}
+	if status >= 400 {
+		return fmt.Errorf("%s returned status %d", target, status)
+	}
+	return nil
+}
+
+func probe(ctx context.Context, client *http.Client, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,80 @@
+package recommend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCheckExternal(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		switch {
+		case name == "missing.md":
+			http.NotFound(w, r)
+		case strings.HasPrefix(name, "nohead") && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case name == "nohead-gone.md":
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	file := func(name string) string { return srv.URL + "/org/repo/raw/branch/main/agents/" + name }
+
+	recs := []Recommendation{
+		{Source: "local", Type: "agents", Name: "dba"},
+		{Source: "hub", Type: "agents", Name: "ok", URL: file("ok.md")},
+		{Source: "hub", Type: "agents", Name: "missing", URL: file("missing.md")},
+		{Source: "hub", Type: "agents", Name: "nohead", URL: file("nohead.md")},
+		{Source: "hub", Type: "agents", Name: "nohead-gone", URL: file("nohead-gone.md")},
+		{Source: "hub", Type: "skills", Name: "pdf", URL: srv.URL + "/pkg.tar.gz#skills/pdf"},
+		{Source: "hub", Type: "skills", Name: "as-file", URL: file("as-file.md")},
+		{Source: "hub", Type: "agents", Name: "as-dir", URL: srv.URL + "/org/repo/src/branch/main/agents"},
+		{Source: "hub", Type: "agents", Name: "git", URL: "git+ssh://git@host/org/repo.git#agents/git.md"},
+		{Source: "hub", Type: "agents", Name: "unsupported", URL: "ftp://host/a.md"},
+	}
+	kept, notes := CheckExternal(context.Background(), srv.Client(), recs)
+
+	var names []string
+	for _, r := range kept {
+		names = append(names, r.Name)
+	}
+	if want := []string{"dba", "ok", "nohead", "pdf", "git"}; !slices.Equal(names, want) {
+		t.Errorf("kept = %v, want %v", names, want)
+	}
+
+	wantNotes := map[string]string{
+		"missing":     "returned status 404",
+		"nohead-gone": "returned status 404",
+		"as-file":     "skills need a repository, directory or archive URL",
+		"as-dir":      "agents must link to a markdown file",
+		"unsupported": "unsupported",
+	}
+	if len(notes) != len(wantNotes) {
+		t.Errorf("notes = %+v", notes)
+	}
+	for _, n := range notes {
+		if want := wantNotes[n.Rec.Name]; !n.Dropped || want == "" || !strings.Contains(n.Message, want) {
+			t.Errorf("note for %s = %q, want %q", n.Rec.Name, n.Message, want)
+		}
+	}
+
+	// HEAD is refused with 405, so these fall back to GET
+	for _, want := range []string{"GET /org/repo/raw/branch/main/agents/nohead.md", "GET /org/repo/raw/branch/main/agents/nohead-gone.md", "HEAD /pkg.tar.gz"} {
+		if !slices.Contains(requests, want) {
+			t.Errorf("requests = %v, missing %s", requests, want)
+		}
+	}
+	if slices.Contains(requests, "GET /org/repo/raw/branch/main/agents/ok.md") {
+		t.Error("a successful HEAD was followed by GET")
+	}
+}
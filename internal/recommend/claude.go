@@ -0,0 +1,87 @@
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ClaudeCLI runs the Claude Code CLI non-interactively.
+type ClaudeCLI struct {
+	Path string // executable; "" looks up "claude" in PATH
+}
+
+// NewClaudeCLI returns a backend using the claude executable in PATH.
+func NewClaudeCLI() *ClaudeCLI {
+	return &ClaudeCLI{}
+}
+
+func (c *ClaudeCLI) Name() string { return "claude CLI" }
+
+func (c *ClaudeCLI) Available() error {
+	if _, err := exec.LookPath(c.path()); err != nil {
+		return fmt.Errorf("claude CLI not found in PATH")
+	}
+	return nil
+}
+
+func (c *ClaudeCLI) path() string {
+	if c.Path != "" {
+		return c.Path
+	}
+	return "claude"
+}
+
+// cliResult is the final message printed by `claude -p --output-format json`.
+type cliResult struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	IsError bool   `json:"is_error"`
+	Result  string `json:"result"`
+}
+
+// Complete runs `claude -p <prompt> --output-format json` and returns the
+// result text of its final message.
+func (c *ClaudeCLI) Complete(ctx context.Context, prompt string) (string, error) {
+	cmd := exec.CommandContext(ctx, c.path(), "-p", prompt, "--output-format", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("exited with code %d: %s", exitErr.ExitCode(), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return parseCLIResult(output)
+}
+
+// parseCLIResult reads the CLI's JSON output: a single result object, or
+// an array of messages ending with one (verbose mode).
+func parseCLIResult(output []byte) (string, error) {
+	output = []byte(strings.TrimSpace(string(output)))
+
+	var res cliResult
+	if err := json.Unmarshal(output, &res); err != nil {
+		var msgs []cliResult
+		if json.Unmarshal(output, &msgs) != nil {
+			return "", fmt.Errorf("unexpected output (not JSON): %.200s", output)
+		}
+		found := false
+		for _, m := range msgs {
+			if m.Type == "result" {
+				res, found = m, true
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("no result message in output")
+		}
+	}
+
+	if res.IsError {
+		return "", fmt.Errorf("error result (%s): %s", res.Subtype, res.Result)
+	}
+	return res.Result, nil
+}
@@ -0,0 +1,28 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+)
+
+// Fake replays canned replies in order, for tests. Prompts are recorded.
+type Fake struct {
+	Replies []string
+	Err     error // returned by Complete when set
+	Prompts []string
+}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) Available() error { return nil }
+
+func (f *Fake) Complete(ctx context.Context, prompt string) (string, error) {
+	f.Prompts = append(f.Prompts, prompt)
+	if f.Err != nil {
+		return "", f.Err
+	}
+	if len(f.Prompts) > len(f.Replies) {
+		return "", fmt.Errorf("fake: no reply for call %d", len(f.Prompts))
+	}
+	return f.Replies[len(f.Prompts)-1], nil
+}
@@ -0,0 +1,143 @@
+package recommend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring the HTTP backend.
+const (
+	EnvHTTPURL   = "BMAD_RECOMMENDER_URL"   // base URL; default DefaultHTTPURL
+	EnvHTTPModel = "BMAD_RECOMMENDER_MODEL" // default DefaultModel
+	EnvAPIKey    = "ANTHROPIC_API_KEY"
+)
+
+// Defaults for the HTTP backend.
+const (
+	DefaultHTTPURL   = "https://api.anthropic.com"
+	DefaultModel     = "claude-sonnet-4-5"
+	anthropicVersion = "2023-06-01"
+	maxReplyTokens   = 4096
+)
+
+// HTTP calls an endpoint compatible with the Anthropic Messages API.
+type HTTP struct {
+	BaseURL string // e.g. "https://api.anthropic.com"; /v1/messages is appended
+	APIKey  string
+	Model   string
+	Client  *http.Client
+}
+
+// NewHTTPFromEnv configures the HTTP backend from the environment.
+func NewHTTPFromEnv() *HTTP {
+	h := &HTTP{
+		BaseURL: os.Getenv(EnvHTTPURL),
+		APIKey:  os.Getenv(EnvAPIKey),
+		Model:   os.Getenv(EnvHTTPModel),
+	}
+	if h.BaseURL == "" {
+		h.BaseURL = DefaultHTTPURL
+	}
+	if h.Model == "" {
+		h.Model = DefaultModel
+	}
+	return h
+}
+
+func (h *HTTP) Name() string { return "messages API at " + h.BaseURL }
+
+func (h *HTTP) Available() error {
+	if h.APIKey == "" {
+		return fmt.Errorf("%s is not set", EnvAPIKey)
+	}
+	return nil
+}
+
+type messagesRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	Messages  []message `json:"messages"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type messagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete posts the prompt as a single user message and returns the
+// concatenated text blocks of the reply.
+func (h *HTTP) Complete(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(messagesRequest{
+		Model:     h.Model,
+		MaxTokens: maxReplyTokens,
+		Messages:  []message{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := strings.TrimRight(h.BaseURL, "/") + "/v1/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("x-api-key", h.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: 120 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("POST %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+
+	var res messagesResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", fmt.Errorf("POST %s: status %d, unexpected body: %.200s", endpoint, resp.StatusCode, data)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if res.Error != nil {
+			return "", fmt.Errorf("POST %s: status %d: %s: %s", endpoint, resp.StatusCode, res.Error.Type, res.Error.Message)
+		}
+		return "", fmt.Errorf("POST %s: status %d", endpoint, resp.StatusCode)
+	}
+
+	var sb strings.Builder
+	for _, block := range res.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("reply has no text (stop reason %q)", res.StopReason)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,159 @@
+// Package recommend asks a language model which components match a smart
+// add query and validates its answer.
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// Recommendation represents a component suggested by smart add, either by
+// a model or by the offline search index.
+type Recommendation struct {
+	Source      string `json:"source"`        // "local", "voltagent", "aitmpl"
+	Type        string `json:"type"`          // a registered catalog kind, e.g. "skills"
+	Name        string `json:"name"`          // component name
+	Description string `json:"description"`   // what it does
+	URL         string `json:"url,omitempty"` // source URL for external components
+}
+
+// Recommender sends a prompt to a model and returns its raw text reply.
+type Recommender interface {
+	// Name identifies the backend in messages, e.g. "claude CLI".
+	Name() string
+	// Available reports why the backend cannot be used, or nil.
+	Available() error
+	// Complete returns the model's reply to prompt.
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// Backend names accepted by New and $BMAD_RECOMMENDER.
+const (
+	BackendClaudeCLI = "claude"
+	BackendHTTP      = "http"
+)
+
+// EnvBackend selects the backend when no explicit name is given.
+const EnvBackend = "BMAD_RECOMMENDER"
+
+// New returns the named backend; "" uses $BMAD_RECOMMENDER, defaulting to
+// the Claude CLI.
+func New(name string) (Recommender, error) {
+	if name == "" {
+		name = os.Getenv(EnvBackend)
+	}
+	switch name {
+	case "", BackendClaudeCLI:
+		return NewClaudeCLI(), nil
+	case BackendHTTP:
+		return NewHTTPFromEnv(), nil
+	}
+	return nil, fmt.Errorf("unknown recommender %q (valid: %s, %s)", name, BackendClaudeCLI, BackendHTTP)
+}
+
+// ErrMalformed marks a reply that is not a valid recommendation list.
+var ErrMalformed = errors.New("malformed recommendations")
+
+// Recommend asks r for recommendations and validates the reply. A
+// malformed reply is retried once, telling the model what was wrong.
+func Recommend(ctx context.Context, r Recommender, prompt string) ([]Recommendation, error) {
+	reply, err := r.Complete(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.Name(), err)
+	}
+	recs, err := Parse(reply)
+	if err == nil {
+		return recs, nil
+	}
+
+	retry := prompt + fmt.Sprintf(`
+
+## CORRECTION
+Your previous reply was rejected: %v
+Reply again with ONLY the JSON array described above.
+`, err)
+	reply, retryErr := r.Complete(ctx, retry)
+	if retryErr != nil {
+		return nil, fmt.Errorf("%s (retry): %w", r.Name(), retryErr)
+	}
+	recs, retryErr = Parse(reply)
+	if retryErr != nil {
+		return nil, fmt.Errorf("%s replied twice with invalid output: %w", r.Name(), retryErr)
+	}
+	return recs, nil
+}
+
+// Parse extracts the recommendation array from a model reply and validates
+// it. The reply may wrap the array in prose or a markdown fence.
+func Parse(reply string) ([]Recommendation, error) {
+	recs, err := extractRecommendations(reply)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// extractRecommendations decodes the first JSON array in s that holds
+// recommendations. Each "[" is tried in turn with a real JSON decoder, so
+// brackets in surrounding prose, inside strings or in other arrays (such as
+// a list of component types) do not confuse it.
+func extractRecommendations(s string) ([]Recommendation, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '[' {
+			continue
+		}
+		var recs []Recommendation
+		if err := json.NewDecoder(strings.NewReader(s[i:])).Decode(&recs); err == nil {
+			return recs, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no JSON array of recommendations in reply", ErrMalformed)
+}
+
+// Validate checks recommendations against the expected schema: a known
+// component type, a plain name, and an absolute URL for external sources.
+// Singular types ("skill") are normalised in place.
+func Validate(recs []Recommendation) error {
+	var problems []string
+	for i := range recs {
+		rec := &recs[i]
+		bad := func(format string, args ...any) {
+			problems = append(problems, fmt.Sprintf("item %d (%s): %s", i+1, rec.Name, fmt.Sprintf(format, args...)))
+		}
+
+		if rec.Source == "" {
+			bad("missing source")
+		}
+		if k, ok := catalog.LookupKind(rec.Type); ok {
+			rec.Type = k.Name()
+		} else {
+			bad("type %q is not one of %s", rec.Type, strings.Join(catalog.KindNames(), ", "))
+		}
+		switch {
+		case strings.TrimSpace(rec.Name) == "":
+			bad("missing name")
+		case strings.HasPrefix(rec.Name, "/") || strings.Contains(rec.Name, "..") || strings.ContainsAny(rec.Name, `\:`):
+			bad("name must be a relative component name")
+		}
+		if rec.Source != "local" {
+			u, err := url.Parse(rec.URL)
+			if rec.URL == "" || err != nil || u.Scheme == "" {
+				bad("external source %q needs an absolute url, got %q", rec.Source, rec.URL)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrMalformed, strings.Join(problems, "; "))
+	}
+	return nil
+}
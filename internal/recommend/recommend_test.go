@@ -0,0 +1,133 @@
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const validReply = `[
+  {"source": "local", "type": "agents", "name": "dba", "description": "Database reviews"},
+  {"source": "voltagent", "type": "skill", "name": "pdf", "description": "PDFs", "url": "https://github.com/org/repo/tree/main/skills/pdf"}
+]`
+
+func TestRecommendValidReply(t *testing.T) {
+	f := &Fake{Replies: []string{validReply}}
+	recs, err := Recommend(context.Background(), f, "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Prompts) != 1 {
+		t.Errorf("prompts = %d, want 1", len(f.Prompts))
+	}
+	if len(recs) != 2 || recs[0].Name != "dba" || recs[1].URL == "" {
+		t.Fatalf("recs = %+v", recs)
+	}
+	if recs[1].Type != "skills" {
+		t.Errorf("type = %q, want singular normalised to skills", recs[1].Type)
+	}
+}
+
+func TestRecommendProseBeforeArray(t *testing.T) {
+	reply := "Here are my picks [see below]:\n```json\n" + validReply + "\n```\nHope [this] helps."
+	f := &Fake{Replies: []string{reply}}
+	recs, err := Recommend(context.Background(), f, "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("recs = %+v", recs)
+	}
+}
+
+func TestParseSkipsOtherArrays(t *testing.T) {
+	reply := `I looked at ["agents","skills"] and [1, 2] before deciding:` + "\n" + validReply
+	recs, err := Parse(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0].Name == "" {
+		t.Fatalf("recs = %+v", recs)
+	}
+
+	if _, err := Parse(`Categories: ["agents","skills"]`); !errors.Is(err, ErrMalformed) {
+		t.Errorf("err = %v, want ErrMalformed", err)
+	}
+}
+
+func TestRecommendRetriesMalformedReply(t *testing.T) {
+	bad := `[{"source": "voltagent", "type": "widgets", "name": "x"}]`
+	f := &Fake{Replies: []string{bad, validReply}}
+	recs, err := Recommend(context.Background(), f, "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("recs = %+v", recs)
+	}
+	if len(f.Prompts) != 2 {
+		t.Fatalf("prompts = %d, want 2", len(f.Prompts))
+	}
+	retry := f.Prompts[1]
+	if !strings.HasPrefix(retry, "prompt") || !strings.Contains(retry, "## CORRECTION") {
+		t.Errorf("retry prompt lacks the correction:\n%s", retry)
+	}
+	if !strings.Contains(retry, `type "widgets"`) || !strings.Contains(retry, "needs an absolute url") {
+		t.Errorf("retry prompt does not explain the problems:\n%s", retry)
+	}
+}
+
+func TestRecommendTwoMalformedReplies(t *testing.T) {
+	f := &Fake{Replies: []string{"no idea", `[{"source": "local"}]`}}
+	_, err := Recommend(context.Background(), f, "prompt")
+	if !errors.Is(err, ErrMalformed) {
+		t.Fatalf("err = %v, want ErrMalformed", err)
+	}
+	if len(f.Prompts) != 2 {
+		t.Errorf("prompts = %d, want 2", len(f.Prompts))
+	}
+}
+
+func TestHTTPComplete(t *testing.T) {
+	var got messagesRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"content": [{"type": "text", "text": "[1,"}, {"type": "tool_use"}, {"type": "text", "text": "2]"}], "stop_reason": "end_turn"}`))
+	}))
+	defer srv.Close()
+
+	h := &HTTP{BaseURL: srv.URL + "/", APIKey: "key", Model: "m"}
+	reply, err := h.Complete(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "[1,2]" {
+		t.Errorf("reply = %q", reply)
+	}
+	if got.Model != "m" || len(got.Messages) != 1 || got.Messages[0].Content != "hello" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestHTTPCompleteError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"type": "error", "error": {"type": "rate_limit_error", "message": "slow down"}}`))
+	}))
+	defer srv.Close()
+
+	h := &HTTP{BaseURL: srv.URL, APIKey: "key", Model: "m"}
+	_, err := h.Complete(context.Background(), "hello")
+	if err == nil || !strings.Contains(err.Error(), "status 429: rate_limit_error: slow down") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package recommend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// minSimilarity is the lowest name similarity (0–1) accepted as a fuzzy
+// match for a local recommendation.
+const minSimilarity = 0.75
+
+// Note explains how a recommendation was corrected or why it was dropped.
+type Note struct {
+	Rec     Recommendation // as recommended
+	Message string
+	Dropped bool
+}
+
+func (n Note) String() string {
+	return fmt.Sprintf("%s/%s [%s]: %s", n.Rec.Type, n.Rec.Name, n.Rec.Source, n.Message)
+}
+
+// ReconcileLocal checks local recommendations against the template
+// catalog. Exact matches are kept; names that are slightly off (or filed
+// under the wrong type) are corrected to the closest real component; the
+// rest are dropped. Non-local recommendations pass through unchanged.
+func ReconcileLocal(recs []Recommendation, categories []catalog.Category) ([]Recommendation, []Note) {
+	var comps []catalog.Component
+	for _, cat := range categories {
+		comps = append(comps, cat.Components...)
+	}
+
+	var kept []Recommendation
+	var notes []Note
+	seen := make(map[string]bool)
+	for _, rec := range recs {
+		if rec.Source != "local" {
+			kept = append(kept, rec)
+			continue
+		}
+
+		c, score := closestComponent(rec, comps)
+		if c == nil || score < minSimilarity {
+			notes = append(notes, Note{Rec: rec, Message: "no such local component", Dropped: true})
+			continue
+		}
+
+		key := c.Type + "/" + c.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if c.Type != rec.Type || c.Name != rec.Name {
+			notes = append(notes, Note{Rec: rec, Message: "matched to " + key})
+		}
+		fixed := rec
+		fixed.Type, fixed.Name = c.Type, c.Name
+		if fixed.Description == "" {
+			fixed.Description = c.Description
+		}
+		kept = append(kept, fixed)
+	}
+	return kept, notes
+}
+
+// closestComponent returns the component whose name is most similar to the
+// recommended one. Components of the recommended type win ties.
+func closestComponent(rec Recommendation, comps []catalog.Component) (*catalog.Component, float64) {
+	name := normalizeName(rec.Type, rec.Name)
+
+	var best *catalog.Component
+	bestScore, bestSameType := -1.0, false
+	for i := range comps {
+		c := &comps[i]
+		score := similarity(name, strings.ToLower(c.Name))
+		sameType := c.Type == rec.Type
+		if score > bestScore || (score == bestScore && sameType && !bestSameType) {
+			best, bestScore, bestSameType = c, score, sameType
+		}
+	}
+	return best, bestScore
+}
+
+// normalizeName strips decoration models commonly add to component names:
+// a "<type>/" prefix, a ".md" suffix, and letter case.
+func normalizeName(kind, name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, kind+"/")
+	if k, ok := catalog.LookupKind(kind); ok {
+		name = strings.TrimPrefix(name, k.Singular()+"/")
+	}
+	name = strings.TrimSuffix(name, ".md")
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// similarity scores two names from 0 (unrelated) to 1 (identical) by edit
+// distance. A name that extends the other at a word boundary
+// ("code-review" vs "code-reviewer") scores at least minSimilarity.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 0
+	}
+	score := 1 - float64(levenshtein(a, b))/float64(longest)
+
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	extends := strings.HasPrefix(long, short+"-") || strings.HasSuffix(long, "-"+short) || strings.HasSuffix(long, "/"+short)
+	if len(short) >= 4 && extends {
+		score = max(score, minSimilarity)
+	}
+	return score
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
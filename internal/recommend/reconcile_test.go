@@ -0,0 +1,138 @@
+package recommend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+var localCatalog = []catalog.Category{
+	{Name: "agents", Components: []catalog.Component{
+		{Type: "agents", Name: "dba", Description: "Database administrator"},
+		{Type: "agents", Name: "docs-writer", Description: "Writes docs"},
+	}},
+	{Name: "skills", Components: []catalog.Component{
+		{Type: "skills", Name: "code-review", Description: "Reviews code"},
+		{Type: "skills", Name: "security/pentest-web", Description: "Web pentesting"},
+	}},
+}
+
+func TestReconcileLocal(t *testing.T) {
+	tests := []struct {
+		name     string
+		rec      Recommendation
+		want     *Recommendation // nil when dropped
+		wantNote string
+	}{
+		{
+			name: "exact match",
+			rec:  Recommendation{Source: "local", Type: "agents", Name: "dba", Description: "Tunes queries"},
+			want: &Recommendation{Source: "local", Type: "agents", Name: "dba", Description: "Tunes queries"},
+		},
+		{
+			name:     "near miss",
+			rec:      Recommendation{Source: "local", Type: "skills", Name: "code-reviewer"},
+			want:     &Recommendation{Source: "local", Type: "skills", Name: "code-review", Description: "Reviews code"},
+			wantNote: "matched to skills/code-review",
+		},
+		{
+			name:     "decorated name",
+			rec:      Recommendation{Source: "local", Type: "agents", Name: "agents/Docs_Writer.md"},
+			want:     &Recommendation{Source: "local", Type: "agents", Name: "docs-writer", Description: "Writes docs"},
+			wantNote: "matched to agents/docs-writer",
+		},
+		{
+			name:     "wrong type",
+			rec:      Recommendation{Source: "local", Type: "agents", Name: "code-review"},
+			want:     &Recommendation{Source: "local", Type: "skills", Name: "code-review", Description: "Reviews code"},
+			wantNote: "matched to skills/code-review",
+		},
+		{
+			name:     "nested name",
+			rec:      Recommendation{Source: "local", Type: "skills", Name: "pentest-web"},
+			want:     &Recommendation{Source: "local", Type: "skills", Name: "security/pentest-web", Description: "Web pentesting"},
+			wantNote: "matched to skills/security/pentest-web",
+		},
+		{
+			name:     "unrelated name",
+			rec:      Recommendation{Source: "local", Type: "agents", Name: "kubernetes-operator"},
+			wantNote: "no such local component",
+		},
+		{
+			name: "external",
+			rec:  Recommendation{Source: "voltagent", Type: "agents", Name: "dbx", URL: "https://example.com/dbx.md"},
+			want: &Recommendation{Source: "voltagent", Type: "agents", Name: "dbx", URL: "https://example.com/dbx.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, notes := ReconcileLocal([]Recommendation{tt.rec}, localCatalog)
+			if tt.want == nil {
+				if len(kept) != 0 {
+					t.Errorf("kept = %+v, want it dropped", kept)
+				}
+			} else if len(kept) != 1 || kept[0] != *tt.want {
+				t.Errorf("kept = %+v, want %+v", kept, *tt.want)
+			}
+
+			var messages []string
+			for _, n := range notes {
+				messages = append(messages, n.Message)
+				if n.Rec != tt.rec || n.Dropped != (tt.want == nil) {
+					t.Errorf("note = %+v", n)
+				}
+			}
+			var want []string
+			if tt.wantNote != "" {
+				want = []string{tt.wantNote}
+			}
+			if !reflect.DeepEqual(messages, want) {
+				t.Errorf("notes = %q, want %q", messages, want)
+			}
+		})
+	}
+}
+
+func TestReconcileLocalCollapsesDuplicates(t *testing.T) {
+	recs := []Recommendation{
+		{Source: "local", Type: "skills", Name: "code-review", Description: "first"},
+		{Source: "local", Type: "skills", Name: "code-reviewer", Description: "second"},
+		{Source: "local", Type: "agents", Name: "code-review", Description: "third"},
+		{Source: "local", Type: "agents", Name: "dba"},
+	}
+	kept, notes := ReconcileLocal(recs, localCatalog)
+	want := []Recommendation{
+		{Source: "local", Type: "skills", Name: "code-review", Description: "first"},
+		{Source: "local", Type: "agents", Name: "dba", Description: "Database administrator"},
+	}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %+v, want %+v", kept, want)
+	}
+	if len(notes) != 0 {
+		t.Errorf("notes = %+v; duplicates are dropped silently", notes)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		near bool // at least minSimilarity
+	}{
+		{"dba", "dba", true},
+		{"code-reviewer", "code-review", true},
+		{"pentest-web", "security/pentest-web", true},
+		{"docs-writer", "doc-writer", true},
+		{"dba", "dbt", false},         // one edit in three letters
+		{"api", "api-gateway", false}, // extends, but shorter than four letters
+		{"kubernetes-operator", "docs-writer", false},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); (got >= minSimilarity) != tt.near {
+			t.Errorf("similarity(%q, %q) = %.2f, want near match %v", tt.a, tt.b, got, tt.near)
+		}
+	}
+	if got := similarity("dba", "dba"); got != 1 {
+		t.Errorf("identical names score %.2f, want 1", got)
+	}
+}
@@ -0,0 +1,189 @@
+// Package search ranks catalog components against free-text queries
+// without network access, using BM25 over component text.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters: k1 controls term-frequency saturation, b the strength
+// of document-length normalisation.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field weights: a query term in a component's name says more than one in
+// its body. Weighted fields are indexed by repeating their tokens.
+const (
+	nameWeight        = 3
+	tagWeight         = 2
+	descriptionWeight = 2
+	bodyWeight        = 1
+)
+
+// Document is a searchable component.
+type Document struct {
+	Source      string   // template source name, e.g. "local"
+	Kind        string   // catalog kind, e.g. "skills"
+	Name        string   // component name, e.g. "security/auth-review"
+	Description string   // frontmatter description
+	Tags        []string // frontmatter tags, keywords and `when:` stacks
+	Body        string   // markdown body without frontmatter
+	URL         string   // install URL, for external catalog entries
+}
+
+// Result is a ranked document.
+type Result struct {
+	Document
+	Score float64
+}
+
+// Index is an in-memory BM25 index.
+type Index struct {
+	docs    []Document
+	terms   []map[string]int // per-document term frequencies
+	lengths []int
+	avgLen  float64
+	df      map[string]int
+}
+
+// NewIndex indexes docs.
+func NewIndex(docs []Document) *Index {
+	ix := &Index{docs: docs, df: make(map[string]int)}
+	total := 0
+	for _, d := range docs {
+		tf := make(map[string]int)
+		n := 0
+		addField := func(text string, weight int) {
+			for _, tok := range Tokenize(text) {
+				tf[tok] += weight
+				n += weight
+			}
+		}
+		addField(d.Name, nameWeight)
+		addField(strings.Join(d.Tags, " "), tagWeight)
+		addField(d.Description, descriptionWeight)
+		addField(d.Body, bodyWeight)
+
+		for tok := range tf {
+			ix.df[tok]++
+		}
+		ix.terms = append(ix.terms, tf)
+		ix.lengths = append(ix.lengths, n)
+		total += n
+	}
+	if len(docs) > 0 {
+		ix.avgLen = float64(total) / float64(len(docs))
+	}
+	return ix
+}
+
+// Len returns the number of indexed documents.
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Search returns documents matching the query, best first. Results scoring
+// below minRelative of the best score are dropped as noise; at most limit
+// results are returned (0 means no limit).
+func (ix *Index) Search(query string, limit int) []Result {
+	const minRelative = 0.2
+
+	qterms := Tokenize(query)
+	if len(qterms) == 0 || len(ix.docs) == 0 {
+		return nil
+	}
+
+	var results []Result
+	for i, tf := range ix.terms {
+		score := 0.0
+		for _, q := range qterms {
+			f := float64(tf[q])
+			if f == 0 {
+				continue
+			}
+			norm := k1 * (1 - b + b*float64(ix.lengths[i])/ix.avgLen)
+			score += ix.idf(q) * f * (k1 + 1) / (f + norm)
+		}
+		if score > 0 {
+			results = append(results, Result{Document: ix.docs[i], Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Kind+"/"+results[i].Name < results[j].Kind+"/"+results[j].Name
+	})
+
+	if len(results) > 0 {
+		cut := results[0].Score * minRelative
+		for i, r := range results {
+			if r.Score < cut {
+				results = results[:i]
+				break
+			}
+		}
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// idf is the BM25 inverse document frequency, kept positive for terms
+// present in most documents.
+func (ix *Index) idf(term string) float64 {
+	n := float64(len(ix.docs))
+	df := float64(ix.df[term])
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// stopwords are ignored in documents and queries.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "how": true, "in": true, "into": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"use": true, "when": true, "with": true, "you": true, "your": true, "i": true, "me": true,
+	"my": true, "want": true, "need": true, "something": true, "some": true, "do": true,
+}
+
+// Tokenize lowercases text, splits it on anything but letters and digits,
+// drops stopwords and one-letter tokens, and reduces common English
+// suffixes so "reviews", "reviewing" and "reviewed" match "review".
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) < 2 || stopwords[f] {
+			continue
+		}
+		tokens = append(tokens, stem(f))
+	}
+	return tokens
+}
+
+// stem strips a few inflectional suffixes. It is deliberately crude: both
+// documents and queries go through it, so it only has to be consistent.
+func stem(w string) string {
+	switch {
+	case len(w) < 5:
+		return w
+	case strings.HasSuffix(w, "ies"):
+		return strings.TrimSuffix(w, "ies") + "y"
+	case strings.HasSuffix(w, "ing"):
+		return strings.TrimSuffix(w, "ing")
+	case strings.HasSuffix(w, "ed"):
+		return strings.TrimSuffix(w, "ed")
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"Reviews, reviewing & reviewed!":       {"review", "review", "review"},
+		"I want something to use with the API": {"api"},
+		"Next.js + React-Native (v2)":          {"next", "js", "react", "native", "v2"},
+		"Dependencies and libraries":           {"dependency", "library"},
+		"class pass go a":                      {"class", "pass", "go"},
+		"Übersetzung für Çağrı":                {"übersetzung", "für", "çağrı"},
+		"security/auth-review":                 {"security", "auth", "review"},
+		"  ":                                   {},
+	}
+	for text, want := range tests {
+		if got := Tokenize(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// corpus is a small catalog: the query terms appear in names, tags,
+// descriptions and bodies of different documents.
+var corpus = []Document{
+	{Kind: "agents", Name: "code-reviewer", Description: "Reviews pull requests for bugs", Body: "Checks style and correctness."},
+	{Kind: "skills", Name: "security/auth-review", Description: "Audit authentication flows", Tags: []string{"security", "oauth"}, Body: "Review login, session and token handling."},
+	{Kind: "skills", Name: "postgres-tuning", Description: "Tune slow queries", Tags: []string{"postgres", "database"}, Body: "Explains query plans and indexes."},
+	{Kind: "commands", Name: "deploy", Description: "Ship to production", Body: "Runs the release pipeline. Mentions security once."},
+	{Kind: "agents", Name: "docs-writer", Description: "Writes documentation", Body: "Drafts READMEs and guides."},
+}
+
+func names(results []Result) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.Kind+"/"+r.Name)
+	}
+	return out
+}
+
+func TestSearchRanking(t *testing.T) {
+	ix := NewIndex(corpus)
+	if ix.Len() != len(corpus) {
+		t.Fatalf("Len = %d", ix.Len())
+	}
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		// The name match outranks tag, description and body matches
+		{"code review", 0, []string{"agents/code-reviewer", "skills/security/auth-review"}},
+		// A tag and name match beats a passing mention in a body
+		{"security", 0, []string{"skills/security/auth-review", "commands/deploy"}},
+		{"slow database queries", 0, []string{"skills/postgres-tuning"}},
+		// "reviewer" is not stemmed, so only the description matches here
+		{"reviewing", 1, []string{"skills/security/auth-review"}},
+		{"kubernetes", 0, nil},
+		{"the and with", 0, nil},
+	}
+	for _, tt := range tests {
+		if got := names(ix.Search(tt.query, tt.limit)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTiesAndNoise(t *testing.T) {
+	ix := NewIndex(append(slices.Clone(corpus),
+		Document{Kind: "skills", Name: "beta", Description: "lint"},
+		Document{Kind: "agents", Name: "alpha", Description: "lint"},
+		Document{Kind: "skills", Name: "noise", Body: "lint " + longBody},
+	))
+	got := names(ix.Search("lint", 0))
+	// Equal scores sort by kind and name; a single mention in a long body
+	// scores below 20% of the best and is dropped
+	if want := []string{"agents/alpha", "skills/beta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search = %v, want %v", got, want)
+	}
+	if got := NewIndex(nil).Search("lint", 0); got != nil {
+		t.Errorf("empty index Search = %v", got)
+	}
+}
+
+var longBody = strings.Repeat("unrelated filler words ", 200)
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// maxBodyBytes bounds how much of a component's body is indexed; the
+// opening sections describe what a component is for.
+const maxBodyBytes = 8 << 10
+
+// CatalogDocuments turns a source's scanned template components into
+// documents, reading each component's main file for its tags and body.
+func CatalogDocuments(source string, categories []catalog.Category) []Document {
+	var docs []Document
+	for _, cat := range categories {
+		for _, c := range cat.Components {
+			doc := Document{Source: source, Kind: cat.Name, Name: c.Name, Description: c.Description, Tags: c.When.Stack}
+			if data, err := os.ReadFile(c.File); err == nil {
+				front, body, ok := catalog.SplitFrontmatter(data)
+				if ok {
+					doc.Tags = append(doc.Tags, frontmatterTags(front)...)
+				}
+				if len(body) > maxBodyBytes {
+					body = body[:maxBodyBytes]
+				}
+				doc.Body = string(body)
+			}
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// frontmatterTags reads `tags:` and `keywords:` as lists or comma-separated
+// strings.
+func frontmatterTags(front []byte) []string {
+	var fm struct {
+		Tags     any `yaml:"tags"`
+		Keywords any `yaml:"keywords"`
+	}
+	if yaml.Unmarshal(front, &fm) != nil {
+		return nil
+	}
+	var tags []string
+	for _, v := range []any{fm.Tags, fm.Keywords} {
+		switch v := v.(type) {
+		case string:
+			for _, t := range strings.Split(v, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					tags = append(tags, t)
+				}
+			}
+		case []any:
+			for _, t := range v {
+				if s, ok := t.(string); ok {
+					tags = append(tags, s)
+				}
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// writeTemplate creates a template directory with files (slash paths to
+// contents) and returns it.
+func writeTemplate(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCatalogDocuments(t *testing.T) {
+	dir := writeTemplate(t, map[string]string{
+		"agents/dba.md":        "---\ndescription: Database administrator\ntags: [postgres, mysql]\nkeywords: indexes, query plans\n---\nTunes slow queries.\n",
+		"skills/sqlx/SKILL.md": "---\nname: sqlx\ndescription: Compile-time checked SQL\nwhen:\n  stack: [rust]\n---\n" + strings.Repeat("x", maxBodyBytes+100),
+	})
+	categories, err := catalog.ScanTemplate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := CatalogDocuments("local", categories)
+	if len(docs) != 2 {
+		t.Fatalf("docs = %+v", docs)
+	}
+
+	dba := docs[0]
+	if dba.Source != "local" || dba.Kind != "agents" || dba.Name != "dba" || dba.Description != "Database administrator" {
+		t.Errorf("dba = %+v", dba)
+	}
+	if want := []string{"postgres", "mysql", "indexes", "query plans"}; !reflect.DeepEqual(dba.Tags, want) {
+		t.Errorf("dba tags = %q, want %q", dba.Tags, want)
+	}
+	if dba.Body != "Tunes slow queries.\n" {
+		t.Errorf("dba body = %q", dba.Body)
+	}
+
+	sqlx := docs[1]
+	if !reflect.DeepEqual(sqlx.Tags, []string{"rust"}) || len(sqlx.Body) != maxBodyBytes {
+		t.Errorf("sqlx tags = %q, body length %d", sqlx.Tags, len(sqlx.Body))
+	}
+
+	// Tags are indexed: "plans" only appears in the dba's keywords
+	if got := names(NewIndex(docs).Search("plan", 0)); !reflect.DeepEqual(got, []string{"agents/dba"}) {
+		t.Errorf("Search = %v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Snippet returns the line of text that mentions the most query terms,
+// trimmed to about maxLen bytes around the first match. Markdown headings,
+// list markers and emphasis are stripped. Returns "" when no line matches.
+func Snippet(text, query string, maxLen int) string {
+	terms := termSet(query)
+	if len(terms) == 0 {
+		return ""
+	}
+
+	best, bestHits, bestHeading := "", 0, false
+	for _, raw := range strings.Split(text, "\n") {
+		heading := strings.HasPrefix(strings.TrimSpace(raw), "#")
+		line := cleanLine(raw)
+		hits := 0
+		seen := make(map[string]bool)
+		for _, tok := range Tokenize(line) {
+			if terms[tok] && !seen[tok] {
+				seen[tok] = true
+				hits++
+			}
+		}
+		// Prose beats a heading with as many matches
+		if hits > bestHits || (hits > 0 && hits == bestHits && bestHeading && !heading) {
+			best, bestHits, bestHeading = line, hits, heading
+		}
+	}
+	if bestHits == 0 {
+		return ""
+	}
+	return trimAround(best, terms, maxLen)
+}
+
+// Highlight wraps every word of text that matches a query term with mark.
+func Highlight(text, query string, mark func(string) string) string {
+	terms := termSet(query)
+	if len(terms) == 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := text[start:end]
+		if toks := Tokenize(word); len(toks) == 1 && terms[toks[0]] {
+			sb.WriteString(mark(word))
+		} else {
+			sb.WriteString(word)
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		sb.WriteRune(r)
+	}
+	flush(len(text))
+	return sb.String()
+}
+
+func termSet(query string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, t := range Tokenize(query) {
+		terms[t] = true
+	}
+	return terms
+}
+
+// cleanLine strips markdown decoration from a line.
+func cleanLine(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimLeft(line, "#>-*+ ")
+	line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+	return strings.TrimSpace(line)
+}
+
+// trimAround cuts line to about maxLen bytes, keeping the first matching
+// word in view and marking cut ends with "…".
+func trimAround(line string, terms map[string]bool, maxLen int) string {
+	if maxLen <= 0 || len(line) <= maxLen {
+		return line
+	}
+
+	first := 0
+	for i := 0; i < len(line); {
+		j := i
+		for j < len(line) && isWordByte(line[j]) {
+			j++
+		}
+		if j > i {
+			if toks := Tokenize(line[i:j]); len(toks) == 1 && terms[toks[0]] {
+				first = i
+				break
+			}
+			i = j
+			continue
+		}
+		i++
+	}
+
+	start := first - maxLen/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + maxLen
+	if end > len(line) {
+		end = len(line)
+		start = max(0, end-maxLen)
+	}
+	// Avoid splitting words (and multi-byte runes) at either end
+	for start > 0 && isWordByte(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordByte(line[end]) {
+		end++
+	}
+
+	out := strings.TrimSpace(line[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(line) {
+		out += "…"
+	}
+	return out
+}
+
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
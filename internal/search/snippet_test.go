@@ -0,0 +1,65 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	text := `# Postgres Review
+
+Overview of the agent.
+
+- **Reviews** slow queries and missing indexes
+Checks postgres migrations for locking problems.
+`
+	tests := []struct {
+		query  string
+		maxLen int
+		want   string
+	}{
+		// The line with the most distinct terms wins
+		{"slow queries", 0, "Reviews slow queries and missing indexes"},
+		// Prose beats a heading with as many matches
+		{"postgres", 0, "Checks postgres migrations for locking problems."},
+		{"review", 0, "Reviews slow queries and missing indexes"},
+		{"overview agent", 0, "Overview of the agent."},
+		{"locking", 24, "…migrations for locking problems…"},
+		{"kubernetes", 0, ""},
+		{"the", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := Snippet(text, tt.query, tt.maxLen); got != tt.want {
+			t.Errorf("Snippet(%q, %d) = %q, want %q", tt.query, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestTrimAround(t *testing.T) {
+	line := "one two three four five six seven eight nine ten"
+	terms := termSet("seven")
+	got := trimAround(line, terms, 20)
+	if !strings.Contains(got, "seven") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("trimAround = %q, want the match in view with both ends cut", got)
+	}
+	// Words are not split
+	for _, w := range strings.Fields(strings.Trim(got, "…")) {
+		if !strings.Contains(line, " "+w+" ") {
+			t.Errorf("trimAround = %q split a word", got)
+		}
+	}
+	if got := trimAround("naïve café résumé", termSet("café"), 8); !strings.Contains(got, "café") {
+		t.Errorf("trimAround = %q, want whole runes around the match", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	mark := func(s string) string { return "[" + s + "]" }
+	got := Highlight("Reviews the reviewer's review, not previews.", "reviewing", mark)
+	if want := "[Reviews] the reviewer's [review], not previews."; got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+	if got := Highlight("unchanged", "the", mark); got != "unchanged" {
+		t.Errorf("Highlight with no terms = %q", got)
+	}
+}
@@ -0,0 +1,230 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StacksDirName holds user-defined stack definitions in the template
+// directory, one YAML file per technology:
+//
+//	name: acme-rpc
+//	category: framework
+//	match:
+//	  dependencies: ["npm:@acme/rpc", "go:github.com/acme/rpc*"]
+//	  files: ["acme.config.*"]
+//	  directories: ["rpc"]
+//	directives:
+//	  - versions: "<2"
+//	    body: |
+//	      ## Acme RPC (v1)
+//	      - ...
+//	  - body: |
+//	      ## Acme RPC
+//	      - ...
+const StacksDirName = "stacks"
+
+// Definition describes how to detect a technology and the directives to
+// emit for it. A definition named like a built-in technology adds match
+// rules to it, and its directives replace the built-in ones.
+type Definition struct {
+	Name       string      `yaml:"name"`
+	Category   string      `yaml:"category"`
+	Match      Match       `yaml:"match"`
+	Directives []Directive `yaml:"directives"`
+	Directive  string      `yaml:"directive"` // shorthand for a single unversioned directive
+
+	Source string `yaml:"-"` // file the definition was loaded from
+}
+
+// Match lists the markers that indicate a technology in a package; any one
+// of them is enough.
+type Match struct {
+	Dependencies []string `yaml:"dependencies"` // "name" or "ecosystem:name"; a trailing "*" matches a prefix
+	Files        []string `yaml:"files"`        // file names or globs relative to the package directory
+	Directories  []string `yaml:"directories"`  // directories relative to the package directory
+}
+
+// Directive is a markdown note limited to a version range (see MatchVersion).
+type Directive struct {
+	Versions string `yaml:"versions"`
+	Body     string `yaml:"body"`
+}
+
+var (
+	definitionsMu sync.RWMutex
+	definitions   []Definition
+)
+
+// LoadDefinitions reads every *.yaml / *.yml file in dir. A missing
+// directory yields no definitions. Files that cannot be read or are invalid
+// are skipped: the valid definitions are returned together with an error
+// naming each bad file.
+func LoadDefinitions(dir string) ([]Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	var defs []Definition
+	var errs []error
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		def, err := loadDefinition(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, errors.Join(errs...)
+}
+
+// loadDefinition reads and validates a single definition file.
+func loadDefinition(path string) (Definition, error) {
+	var def Definition
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return def, err
+	}
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return def, fmt.Errorf("parsing: %w", err)
+	}
+	if err := def.normalize(); err != nil {
+		return def, err
+	}
+	def.Source = path
+	return def, nil
+}
+
+// normalize validates a definition and applies defaults.
+func (d *Definition) normalize() error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if d.Category == "" {
+		d.Category = "framework"
+	}
+	if d.Directive != "" {
+		d.Directives = append(d.Directives, Directive{Body: d.Directive})
+		d.Directive = ""
+	}
+	for i, dir := range d.Directives {
+		if strings.TrimSpace(dir.Body) == "" {
+			return fmt.Errorf("directive %d has no body", i+1)
+		}
+		d.Directives[i].Body = strings.TrimRight(dir.Body, "\n")
+	}
+	for _, dep := range d.Match.Dependencies {
+		if eco, _, ok := strings.Cut(dep, ":"); ok && isEcosystem(eco) && strings.TrimPrefix(dep, eco+":") == "" {
+			return fmt.Errorf("empty dependency name in %q", dep)
+		}
+	}
+	return nil
+}
+
+// RegisterDefinitions adds user-defined technologies to detection. Later
+// registrations of the same name replace earlier ones.
+func RegisterDefinitions(defs ...Definition) {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	for _, def := range defs {
+		replaced := false
+		for i := range definitions {
+			if definitions[i].Name == def.Name {
+				definitions[i] = def
+				replaced = true
+			}
+		}
+		if !replaced {
+			definitions = append(definitions, def)
+		}
+	}
+}
+
+// ResetDefinitions removes all registered definitions, e.g. before
+// reloading them from a changed template.
+func ResetDefinitions() {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	definitions = nil
+}
+
+// Definitions returns the registered user-defined technologies.
+func Definitions() []Definition {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	return append([]Definition(nil), definitions...)
+}
+
+// LookupDefinition returns the registered definition for a technology.
+func LookupDefinition(name string) (Definition, bool) {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	for _, d := range definitions {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+func isEcosystem(s string) bool {
+	switch s {
+	case EcosystemNPM, EcosystemPyPI, EcosystemRubyGems, EcosystemComposer, EcosystemGo, EcosystemCargo, EcosystemMaven:
+		return true
+	}
+	return false
+}
+
+// tech returns the technology a definition detects.
+func (d Definition) tech() Tech {
+	return Tech{Name: d.Name, Category: d.Category}
+}
+
+// dependencyRules converts the definition's dependency matchers to rules.
+// Without an ecosystem prefix a name matches in any ecosystem.
+func (d Definition) dependencyRules() []dependencyRule {
+	var rules []dependencyRule
+	for _, dep := range d.Match.Dependencies {
+		r := dependencyRule{Package: dep, Tech: d.tech()}
+		if eco, name, ok := strings.Cut(dep, ":"); ok && isEcosystem(eco) {
+			r.Ecosystem, r.Package = eco, name
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// matchedMarker returns the first of the definition's file or directory
+// markers present in dir, relative to dir, or "".
+func (d Definition) matchedMarker(dir string) string {
+	for _, f := range d.Match.Files {
+		if matches, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(f))); len(matches) > 0 {
+			rel, _ := filepath.Rel(dir, matches[0])
+			return filepath.ToSlash(rel)
+		}
+	}
+	for _, sub := range d.Match.Directories {
+		if info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(sub))); err == nil && info.IsDir() {
+			return sub
+		}
+	}
+	return ""
+}
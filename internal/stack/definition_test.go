@@ -0,0 +1,45 @@
+package stack
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDefinitionsSkipsInvalidFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"acme-rpc.yaml":   "name: acme-rpc\nmatch:\n  dependencies: [\"npm:@acme/rpc\"]\ndirective: |\n  ## Acme RPC\n",
+		"broken.yaml":     "name: [unclosed\n",
+		"nameless.yml":    "category: tool\n",
+		"empty-body.yaml": "name: widget\ndirectives:\n  - versions: \"<2\"\n    body: \"\"\n",
+		"zeta.yml":        "name: zeta\ncategory: tool\n",
+		"notes.txt":       "not a definition",
+	})
+
+	defs, err := LoadDefinitions(dir)
+	if err == nil {
+		t.Fatal("want an error naming the invalid files")
+	}
+	for _, want := range []string{"broken.yaml: parsing", "nameless.yml: missing name", "empty-body.yaml: directive 1 has no body"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, missing %q", err, want)
+		}
+	}
+	if len(defs) != 2 || defs[0].Name != "acme-rpc" || defs[1].Name != "zeta" {
+		t.Fatalf("defs = %+v, want the valid acme-rpc and zeta", defs)
+	}
+	acme := defs[0]
+	if acme.Category != "framework" || len(acme.Directives) != 1 || acme.Directives[0].Body != "## Acme RPC" {
+		t.Errorf("acme-rpc = %+v", acme)
+	}
+	if acme.Source != filepath.Join(dir, "acme-rpc.yaml") {
+		t.Errorf("source = %q", acme.Source)
+	}
+}
+
+func TestLoadDefinitionsMissingDir(t *testing.T) {
+	defs, err := LoadDefinitions(filepath.Join(t.TempDir(), "stacks"))
+	if defs != nil || err != nil {
+		t.Errorf("LoadDefinitions = %v, %v; want nothing", defs, err)
+	}
+}
@@ -3,16 +3,34 @@ package stack
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 )
 
 // Tech represents a detected technology.
 type Tech struct {
-	Name     string
-	Category string // "language", "framework", "runtime", "database", "cloud", "tool"
+	Name     string     `json:"name"`
+	Category string     `json:"category"`          // "language", "framework", "runtime", "database", "messaging", "cloud", "test", "lint", "ci", "tool"
+	Version  string     `json:"version,omitempty"` // resolved from a lockfile, else the declared constraint's lower bound; "" if unknown
+	Evidence []Evidence `json:"evidence,omitempty"`
+}
+
+// Evidence records a file and rule that triggered a detection.
+type Evidence struct {
+	File string `json:"file"` // slash-separated, relative to the project root
+	Rule string `json:"rule"` // e.g. "npm dependency next ^14.0.0, locked 14.1.3"
+}
+
+// NearMiss is a file detection looked at without recognising a technology,
+// e.g. a compose service with an unknown image or a dependency named like
+// a known framework ("react-native").
+type NearMiss struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
 }
 
 // depFileMap maps dependency files to the technologies they indicate.
@@ -63,6 +81,12 @@ var depFileMap = map[string][]Tech{
 	"docker-compose.yaml": {
 		{Name: "docker-compose", Category: "tool"},
 	},
+	"compose.yml": {
+		{Name: "docker-compose", Category: "tool"},
+	},
+	"compose.yaml": {
+		{Name: "docker-compose", Category: "tool"},
+	},
 	".terraform.lock.hcl": {
 		{Name: "terraform", Category: "tool"},
 	},
@@ -71,119 +95,392 @@ var depFileMap = map[string][]Tech{
 	},
 }
 
-// frameworkDetectors check for framework-specific markers in package.json or similar.
-type frameworkDetector struct {
-	File      string
-	Contains  string
-	Framework Tech
-}
-
-var frameworkDetectors = []frameworkDetector{
-	// JavaScript/TypeScript frameworks (check package.json content)
-	{"package.json", `"next"`, Tech{Name: "nextjs", Category: "framework"}},
-	{"package.json", `"react"`, Tech{Name: "react", Category: "framework"}},
-	{"package.json", `"vue"`, Tech{Name: "vue", Category: "framework"}},
-	{"package.json", `"nuxt"`, Tech{Name: "nuxt", Category: "framework"}},
-	{"package.json", `"svelte"`, Tech{Name: "svelte", Category: "framework"}},
-	{"package.json", `"@angular/core"`, Tech{Name: "angular", Category: "framework"}},
-	{"package.json", `"express"`, Tech{Name: "express", Category: "framework"}},
-	{"package.json", `"fastify"`, Tech{Name: "fastify", Category: "framework"}},
-	{"package.json", `"hono"`, Tech{Name: "hono", Category: "framework"}},
-	{"package.json", `"nest"`, Tech{Name: "nestjs", Category: "framework"}},
-	{"package.json", `"@nestjs/core"`, Tech{Name: "nestjs", Category: "framework"}},
-	{"package.json", `"tailwindcss"`, Tech{Name: "tailwind", Category: "framework"}},
-	{"package.json", `"prisma"`, Tech{Name: "prisma", Category: "tool"}},
-	{"package.json", `"drizzle-orm"`, Tech{Name: "drizzle", Category: "tool"}},
-	// Python frameworks (check requirements.txt or pyproject.toml)
-	{"requirements.txt", "django", Tech{Name: "django", Category: "framework"}},
-	{"requirements.txt", "flask", Tech{Name: "flask", Category: "framework"}},
-	{"requirements.txt", "fastapi", Tech{Name: "fastapi", Category: "framework"}},
-	{"pyproject.toml", "django", Tech{Name: "django", Category: "framework"}},
-	{"pyproject.toml", "flask", Tech{Name: "flask", Category: "framework"}},
-	{"pyproject.toml", "fastapi", Tech{Name: "fastapi", Category: "framework"}},
+// dependencyRule maps a dependency declared in a manifest to a technology.
+type dependencyRule struct {
+	Ecosystem string // "" matches any ecosystem
+	Package   string // normalised package name; a trailing "*" matches a prefix
+	Tech      Tech
+}
+
+var dependencyRules = []dependencyRule{
+	// JavaScript/TypeScript frameworks
+	{EcosystemNPM, "next", Tech{Name: "nextjs", Category: "framework"}},
+	{EcosystemNPM, "react", Tech{Name: "react", Category: "framework"}},
+	{EcosystemNPM, "vue", Tech{Name: "vue", Category: "framework"}},
+	{EcosystemNPM, "nuxt", Tech{Name: "nuxt", Category: "framework"}},
+	{EcosystemNPM, "svelte", Tech{Name: "svelte", Category: "framework"}},
+	{EcosystemNPM, "@angular/core", Tech{Name: "angular", Category: "framework"}},
+	{EcosystemNPM, "express", Tech{Name: "express", Category: "framework"}},
+	{EcosystemNPM, "fastify", Tech{Name: "fastify", Category: "framework"}},
+	{EcosystemNPM, "hono", Tech{Name: "hono", Category: "framework"}},
+	{EcosystemNPM, "@nestjs/core", Tech{Name: "nestjs", Category: "framework"}},
+	{EcosystemNPM, "tailwindcss", Tech{Name: "tailwind", Category: "framework"}},
+	{EcosystemNPM, "prisma", Tech{Name: "prisma", Category: "tool"}},
+	{EcosystemNPM, "@prisma/client", Tech{Name: "prisma", Category: "tool"}},
+	{EcosystemNPM, "drizzle-orm", Tech{Name: "drizzle", Category: "tool"}},
+	// Python frameworks
+	{EcosystemPyPI, "django", Tech{Name: "django", Category: "framework"}},
+	{EcosystemPyPI, "flask", Tech{Name: "flask", Category: "framework"}},
+	{EcosystemPyPI, "fastapi", Tech{Name: "fastapi", Category: "framework"}},
 	// Ruby frameworks
-	{"Gemfile", "rails", Tech{Name: "rails", Category: "framework"}},
-	{"Gemfile", "sinatra", Tech{Name: "sinatra", Category: "framework"}},
+	{EcosystemRubyGems, "rails", Tech{Name: "rails", Category: "framework"}},
+	{EcosystemRubyGems, "sinatra", Tech{Name: "sinatra", Category: "framework"}},
 	// PHP frameworks
-	{"composer.json", "laravel", Tech{Name: "laravel", Category: "framework"}},
-	{"composer.json", "symfony", Tech{Name: "symfony", Category: "framework"}},
+	{EcosystemComposer, "laravel/framework", Tech{Name: "laravel", Category: "framework"}},
+	{EcosystemComposer, "symfony/framework-bundle", Tech{Name: "symfony", Category: "framework"}},
+	{EcosystemComposer, "symfony/symfony", Tech{Name: "symfony", Category: "framework"}},
+	// Go frameworks
+	{EcosystemGo, "github.com/gin-gonic/gin", Tech{Name: "gin", Category: "framework"}},
+	{EcosystemGo, "github.com/labstack/echo*", Tech{Name: "echo", Category: "framework"}},
+	{EcosystemGo, "github.com/gofiber/fiber*", Tech{Name: "fiber", Category: "framework"}},
+	// Rust frameworks
+	{EcosystemCargo, "axum", Tech{Name: "axum", Category: "framework"}},
+	{EcosystemCargo, "actix-web", Tech{Name: "actix-web", Category: "framework"}},
+	// Java frameworks
+	{EcosystemMaven, "org.springframework.boot:*", Tech{Name: "spring-boot", Category: "framework"}},
 }
 
-// directoryDetectors check for the presence of directories.
+// matches reports whether the rule applies to a declared dependency.
+// Indirect and dependency-management entries are not used by the project.
+func (r dependencyRule) matches(d Dependency) bool {
+	return d.Scope != "indirect" && d.Scope != "managed" && r.matchesName(d)
+}
+
+// matchesName reports whether the rule names the dependency, in any scope.
+func (r dependencyRule) matchesName(d Dependency) bool {
+	if r.Ecosystem != "" && d.Ecosystem != r.Ecosystem {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Package, "*"); ok {
+		return strings.HasPrefix(d.Name, prefix)
+	}
+	return d.Name == r.Package
+}
+
+// directoryDetectors check for the presence of directories. CI systems are
+// detected from their config files (see ciFiles).
 var directoryDetectors = map[string]Tech{
-	".github":    {Name: "github-actions", Category: "tool"},
-	".gitlab-ci": {Name: "gitlab-ci", Category: "tool"},
-	"k8s":        {Name: "kubernetes", Category: "tool"},
-	"helm":       {Name: "helm", Category: "tool"},
-	"infra":      {Name: "infrastructure", Category: "tool"},
+	"k8s":   {Name: "kubernetes", Category: "tool"},
+	"helm":  {Name: "helm", Category: "tool"},
+	"infra": {Name: "infrastructure", Category: "tool"},
+}
+
+// Package is a directory of the project with its own dependency manifest,
+// such as a workspace member in a monorepo. The root is always a package.
+type Package struct {
+	Path       string     `json:"path"` // relative to the project root, slash-separated; "." for the root
+	Techs      []Tech     `json:"techs"`
+	Commands   Commands   `json:"commands"` // canonical test/lint/build commands, run from Path
+	NearMisses []NearMiss `json:"nearMisses,omitempty"`
 }
 
-// DetectStack scans a project root and returns detected technologies.
+// DetectStack scans a project (including nested packages) and returns the
+// union of detected technologies.
 func DetectStack(projectRoot string) []Tech {
-	seen := make(map[string]bool)
-	var techs []Tech
+	return Merge(DetectPackages(projectRoot))
+}
 
-	addTech := func(t Tech) {
-		if !seen[t.Name] {
-			seen[t.Name] = true
+// Merge returns the union of the packages' technologies, sorted by name.
+// A technology found in several packages keeps the first version found and
+// the evidence from all of them.
+func Merge(packages []Package) []Tech {
+	index := make(map[string]int)
+	var techs []Tech
+	for _, pkg := range packages {
+		for _, t := range pkg.Techs {
+			if i, ok := index[t.Name]; ok {
+				techs[i].Evidence = append(techs[i].Evidence, t.Evidence...)
+				continue
+			}
+			index[t.Name] = len(techs)
+			t.Evidence = append([]Evidence(nil), t.Evidence...)
 			techs = append(techs, t)
 		}
 	}
 
+	sort.Slice(techs, func(i, j int) bool {
+		return techs[i].Name < techs[j].Name
+	})
+
+	return techs
+}
+
+// DetectPackages finds the packages of a project — workspace members
+// (pnpm/npm/yarn workspaces, go.work, Cargo, Nx, Lerna) and any directory
+// holding a dependency manifest — and detects technologies in each. The walk
+// honours .gitignore and skips node_modules, vendor and build output.
+// Packages without any detected technology are omitted, except the root.
+func DetectPackages(projectRoot string) []Package {
+	var packages []Package
+	for _, dir := range packageDirs(projectRoot) {
+		techs, misses := detectDir(projectRoot, dir)
+		if dir != "." && len(techs) == 0 {
+			continue
+		}
+		packages = append(packages, Package{
+			Path:       dir,
+			Techs:      techs,
+			Commands:   detectCommands(projectRoot, dir, techs),
+			NearMisses: misses,
+		})
+	}
+	return packages
+}
+
+// packageDirs returns the root plus every workspace member and manifest
+// directory, sorted with the root first.
+func packageDirs(projectRoot string) []string {
+	dirs := map[string]bool{".": true}
+
+	for _, ws := range DetectWorkspaces(projectRoot) {
+		for _, m := range ws.Members {
+			dirs[m] = true
+		}
+	}
+
+	WalkDirs(projectRoot, func(rel string) {
+		if hasManifest(filepath.Join(projectRoot, filepath.FromSlash(rel))) {
+			dirs[rel] = true
+		}
+	})
+
+	sorted := make([]string, 0, len(dirs))
+	for d := range dirs {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i] == "." || sorted[j] == "." {
+			return sorted[i] == "."
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// hasManifest reports whether dir contains a language dependency manifest.
+func hasManifest(dir string) bool {
+	for file, techs := range depFileMap {
+		if !isManifestTechs(techs) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isManifestTechs reports whether a dependency file marks a package, i.e.
+// it indicates a language or runtime rather than a tool like Docker.
+func isManifestTechs(techs []Tech) bool {
+	for _, t := range techs {
+		if t.Category == "language" || t.Category == "runtime" {
+			return true
+		}
+	}
+	return false
+}
+
+// detection accumulates the technologies, evidence and near misses of one
+// package directory.
+type detection struct {
+	projectRoot string
+	techs       []Tech
+	index       map[string]int
+	nearMisses  []NearMiss
+}
+
+// add records a technology found by rule in path (absolute). A technology
+// seen before only gains evidence, and a version if it had none.
+func (d *detection) add(t Tech, path, rule string) {
+	ev := Evidence{File: d.rel(path), Rule: rule}
+	if i, ok := d.index[t.Name]; ok {
+		d.techs[i].Evidence = append(d.techs[i].Evidence, ev)
+		if d.techs[i].Version == "" {
+			d.techs[i].Version = t.Version
+		}
+		return
+	}
+	t.Evidence = []Evidence{ev}
+	d.index[t.Name] = len(d.techs)
+	d.techs = append(d.techs, t)
+}
+
+// miss records a near miss for path (absolute).
+func (d *detection) miss(path, reason string) {
+	d.nearMisses = append(d.nearMisses, NearMiss{File: d.rel(path), Reason: reason})
+}
+
+// has reports whether a technology was detected.
+func (d *detection) has(name string) bool {
+	_, ok := d.index[name]
+	return ok
+}
+
+func (d *detection) rel(path string) string {
+	if r, err := filepath.Rel(d.projectRoot, path); err == nil {
+		return filepath.ToSlash(r)
+	}
+	return filepath.ToSlash(path)
+}
+
+// detectDir detects technologies in one package directory (relative to
+// the project root). Repository-level markers such as CI config and k8s/
+// are only checked for the root.
+func detectDir(projectRoot, rel string) ([]Tech, []NearMiss) {
+	dir := filepath.Join(projectRoot, filepath.FromSlash(rel))
+	d := &detection{projectRoot: projectRoot, index: make(map[string]int)}
+
 	// Check for dependency files
 	for file, fileTechs := range depFileMap {
-		if _, err := os.Stat(filepath.Join(projectRoot, file)); err == nil {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
 			for _, t := range fileTechs {
-				addTech(t)
+				d.add(t, filepath.Join(dir, file), "dependency file "+file)
 			}
 		}
 	}
 
 	// Check for .tf files (Terraform)
-	if matches, _ := filepath.Glob(filepath.Join(projectRoot, "*.tf")); len(matches) > 0 {
-		addTech(Tech{Name: "terraform", Category: "tool"})
+	for _, pattern := range []string{"*.tf", filepath.Join("infra", "*.tf")} {
+		if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+			d.add(Tech{Name: "terraform", Category: "tool"}, matches[0], "Terraform file "+filepath.ToSlash(pattern))
+		}
 	}
-	if matches, _ := filepath.Glob(filepath.Join(projectRoot, "infra", "*.tf")); len(matches) > 0 {
-		addTech(Tech{Name: "terraform", Category: "tool"})
+
+	// Check declared dependencies, resolving versions from lockfiles
+	custom := Definitions()
+	rules := append(dependencyRules[:len(dependencyRules):len(dependencyRules)], serviceDependencyRules...)
+	rules = append(rules, toolingDependencyRules...)
+	builtin := len(rules)
+	for _, def := range custom {
+		rules = append(rules[:len(rules):len(rules)], def.dependencyRules()...)
 	}
 
-	// Check for framework-specific markers
-	for _, d := range frameworkDetectors {
-		filePath := filepath.Join(projectRoot, d.File)
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			continue
+	deps := DirDependencies(dir)
+	var locks map[string]lockIndex
+	if len(deps) > 0 {
+		locks = lockedVersions(projectRoot, rel)
+	}
+	for _, dep := range deps {
+		manifest := filepath.Join(dir, dep.Manifest)
+		matched := false
+		for i, r := range rules {
+			if !r.matchesName(dep) {
+				continue
+			}
+			if !r.matches(dep) {
+				d.miss(manifest, fmt.Sprintf("%s dependency %s is %s; only direct dependencies count", dep.Ecosystem, dep.Name, dep.Scope))
+				matched = true
+				continue
+			}
+			matched = true
+			t := r.Tech
+			rule := fmt.Sprintf("%s dependency %s", dep.Ecosystem, dep.Name)
+			if dep.Constraint != "" {
+				rule += " " + dep.Constraint
+			}
+			if i >= builtin {
+				rule += " (stack definition)"
+			}
+			if isServiceCategory(t.Category) {
+				// A client SDK's version says nothing about the server
+				d.add(t, manifest, rule)
+				continue
+			}
+			if t.Version = locks[dep.Ecosystem].lookup(dep); t.Version != "" {
+				rule += ", locked " + t.Version
+			} else {
+				t.Version = constraintFloor(dep.Constraint)
+			}
+			t.Version = strings.TrimPrefix(t.Version, "v")
+			d.add(t, manifest, rule)
 		}
-		if strings.Contains(string(content), d.Contains) {
-			addTech(d.Framework)
+		if !matched {
+			if r, ok := similarRule(rules, dep); ok {
+				d.miss(manifest, fmt.Sprintf("%s dependency %s is not %s (package names must match exactly)", dep.Ecosystem, dep.Name, r.Package))
+			}
 		}
 	}
-
-	// Check for directories
-	for dir, t := range directoryDetectors {
-		if info, err := os.Stat(filepath.Join(projectRoot, dir)); err == nil && info.IsDir() {
-			addTech(t)
+	// User-defined file and directory markers
+	for _, def := range custom {
+		if marker := def.matchedMarker(dir); marker != "" {
+			d.add(def.tech(), filepath.Join(dir, filepath.FromSlash(marker)), "stack definition "+filepath.Base(def.Source))
 		}
 	}
 
-	// Check for .github/workflows specifically
-	if _, err := os.Stat(filepath.Join(projectRoot, ".github", "workflows")); err == nil {
-		addTech(Tech{Name: "github-actions", Category: "tool"})
+	if v := goDirective(filepath.Join(dir, "go.mod")); v != "" && d.has("go") {
+		d.techs[d.index["go"]].Version = v
 	}
 
+	// Databases, brokers and cloud providers from compose, IaC and config
+	detectServices(d, dir, rel == ".")
+
+	// Test frameworks, linters and CI systems
+	detectTooling(d, dir, rel == ".")
+
+	if rel == "." {
+		// Check for directories
+		for sub, t := range directoryDetectors {
+			if info, err := os.Stat(filepath.Join(dir, sub)); err == nil && info.IsDir() {
+				d.add(t, filepath.Join(dir, sub), "directory "+sub+"/")
+			}
+		}
+	}
+
+	techs := d.techs
 	sort.Slice(techs, func(i, j int) bool {
 		return techs[i].Name < techs[j].Name
 	})
 
-	return techs
+	return techs, d.nearMisses
+}
+
+// similarRule finds a rule whose package name is a prefix or suffix
+// component of dep's name, e.g. react for react-native or @types/react.
+func similarRule(rules []dependencyRule, dep Dependency) (dependencyRule, bool) {
+	for _, r := range rules {
+		if r.Ecosystem != "" && r.Ecosystem != dep.Ecosystem {
+			continue
+		}
+		if strings.HasSuffix(r.Package, "*") || len(r.Package) < 3 {
+			continue
+		}
+		for _, sep := range []string{"-", "/", "."} {
+			if strings.HasPrefix(dep.Name, r.Package+sep) || strings.HasSuffix(dep.Name, sep+r.Package) {
+				return r, true
+			}
+		}
+	}
+	return dependencyRule{}, false
 }
 
-// ListDependencyFiles returns the list of dependency files present in the project.
+var goVersionLine = regexp.MustCompile(`(?m)^go\s+(\S+)\s*$`)
+
+// goDirective returns the language version declared by a go.mod `go` line.
+func goDirective(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if m := goVersionLine.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// ListDependencyFiles returns the dependency files present in the project
+// and its packages, as slash-separated paths relative to the root.
 func ListDependencyFiles(projectRoot string) []string {
 	var files []string
-	for file := range depFileMap {
-		if _, err := os.Stat(filepath.Join(projectRoot, file)); err == nil {
-			files = append(files, file)
+	for _, dir := range packageDirs(projectRoot) {
+		for file := range depFileMap {
+			rel := file
+			if dir != "." {
+				rel = dir + "/" + file
+			}
+			if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(rel))); err == nil {
+				files = append(files, rel)
+			}
 		}
 	}
 	sort.Strings(files)
@@ -200,7 +497,7 @@ func ComputeDependencyHash(projectRoot string) string {
 
 	h := sha256.New()
 	for _, f := range files {
-		data, err := os.ReadFile(filepath.Join(projectRoot, f))
+		data, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(f)))
 		if err != nil {
 			continue
 		}
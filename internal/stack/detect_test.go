@@ -0,0 +1,173 @@
+package stack
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWalkDirs(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":                 "# build output\nbuild/\n/tmp\n*.gen\n!keep.gen\ndocs/**/out\n",
+		"src/app/main.go":            "",
+		"build/x":                    "",
+		"tmp/x":                      "",
+		"src/tmp/x":                  "",
+		"src/api.gen/x":              "",
+		"src/keep.gen/x":             "",
+		"docs/guide/out/x":           "",
+		"docs/guide/src/x":           "",
+		"node_modules/react/x":       "",
+		"services/node_modules/a/x":  "",
+		"vendor/x":                   "",
+		".git/objects/x":             "",
+		"target/debug/x":             "",
+		"services/web/.gitignore":    "/private\ncache\n",
+		"services/web/private/x":     "",
+		"services/web/lib/private/x": "",
+		"services/web/lib/cache/x":   "",
+	})
+
+	var got []string
+	WalkDirs(root, func(rel string) { got = append(got, rel) })
+	slices.Sort(got)
+	want := []string{
+		".",
+		"docs", "docs/guide", "docs/guide/src",
+		"services", "services/web", "services/web/lib", "services/web/lib/private",
+		"src", "src/app", "src/keep.gen", "src/tmp",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkDirs =\n  %v\nwant\n  %v", got, want)
+	}
+}
+
+func TestWalkDirsDepth(t *testing.T) {
+	root := writeTree(t, map[string]string{"a/b/c/d/e/f/g/h/x": ""})
+	var deepest string
+	WalkDirs(root, func(rel string) { deepest = rel })
+	if deepest != "a/b/c/d/e/f" {
+		t.Errorf("deepest = %q, want the walk to stop at depth %d", deepest, maxWalkDepth)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"packages/*", "packages/web", true},
+		{"packages/*", "packages/web/src", false},
+		{"libs/**", "libs/a/b", true},
+		{"libs/**", "libs", false},
+		{"**/out", "out", true},
+		{"**/out", "docs/guide/out", true},
+		{"docs/**/out", "docs/out", true},
+		{"docs/**/out", "src/out", false},
+		{"*.gen", "api.gen", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectPackages(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":                       "generated/\n",
+		"package.json":                     `{"workspaces": ["apps/*"], "devDependencies": {"turbo": "^2.0.0"}}`,
+		"apps/web/package.json":            `{"dependencies": {"next": "^14.1.0", "react": "^18.2.0"}}`,
+		"apps/web/tsconfig.json":           "{}",
+		"apps/docs/README.md":              "no manifest, no techs",
+		"services/api/go.mod":              "module example.com/api\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+		"generated/client/package.json":    `{"dependencies": {"express": "4"}}`,
+		"node_modules/next/package.json":   `{"dependencies": {"react": "18"}}`,
+		"apps/web/node_modules/x/setup.py": "",
+	})
+
+	packages := DetectPackages(root)
+	var paths []string
+	for _, p := range packages {
+		paths = append(paths, p.Path)
+	}
+	if want := []string{".", "apps/web", "services/api"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("packages = %v, want %v", paths, want)
+	}
+
+	web := techNames(packages[1].Techs)
+	for _, want := range []string{"javascript", "nextjs", "node", "react", "typescript"} {
+		if !slices.Contains(web, want) {
+			t.Errorf("apps/web techs = %v, missing %s", web, want)
+		}
+	}
+	api := packages[2].Techs
+	if !slices.Contains(techNames(api), "gin") {
+		t.Errorf("services/api techs = %v, missing gin", techNames(api))
+	}
+	for _, tech := range api {
+		if tech.Name == "go" && tech.Version != "1.22" {
+			t.Errorf("go version = %q, want the go directive 1.22", tech.Version)
+		}
+	}
+
+	// DetectStack is the union of all packages
+	all := techNames(DetectStack(root))
+	if !slices.IsSorted(all) || !slices.Contains(all, "nextjs") || !slices.Contains(all, "gin") || slices.Contains(all, "express") {
+		t.Errorf("DetectStack = %v", all)
+	}
+}
+
+func TestDetectNearMiss(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"package.json": `{"dependencies": {"react-native": "0.73.0", "@types/react": "18"}}`,
+	})
+	packages := DetectPackages(root)
+	if len(packages) != 1 {
+		t.Fatalf("packages = %+v", packages)
+	}
+	if techs := techNames(packages[0].Techs); slices.Contains(techs, "react") {
+		t.Errorf("techs = %v; react-native is not react", techs)
+	}
+	var reasons []string
+	for _, m := range packages[0].NearMisses {
+		reasons = append(reasons, m.Reason)
+		if m.File != "package.json" {
+			t.Errorf("near miss file = %q", m.File)
+		}
+	}
+	joined := strings.Join(reasons, "\n")
+	for _, want := range []string{"npm dependency react-native is not react", "npm dependency @types/react is not react"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("near misses = %q, missing %q", joined, want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	packages := []Package{
+		{Path: ".", Techs: []Tech{
+			{Name: "node", Category: "runtime", Evidence: []Evidence{{File: "package.json"}}},
+		}},
+		{Path: "apps/web", Techs: []Tech{
+			{Name: "react", Category: "framework", Version: "18.2.0", Evidence: []Evidence{{File: "apps/web/package.json"}}},
+			{Name: "node", Category: "runtime", Evidence: []Evidence{{File: "apps/web/package.json"}}},
+		}},
+		{Path: "apps/admin", Techs: []Tech{
+			{Name: "react", Category: "framework", Version: "17.0.2", Evidence: []Evidence{{File: "apps/admin/package.json"}}},
+		}},
+	}
+	got := Merge(packages)
+	want := []Tech{
+		{Name: "node", Category: "runtime", Evidence: []Evidence{{File: "package.json"}, {File: "apps/web/package.json"}}},
+		{Name: "react", Category: "framework", Version: "18.2.0", Evidence: []Evidence{{File: "apps/web/package.json"}, {File: "apps/admin/package.json"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge =\n  %+v\nwant\n  %+v", got, want)
+	}
+	// The packages' evidence is not aliased
+	if len(packages[0].Techs[0].Evidence) != 1 {
+		t.Errorf("Merge modified its input: %+v", packages[0].Techs[0].Evidence)
+	}
+}
@@ -0,0 +1,187 @@
+package stack
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// skipDirs are never descended into, regardless of .gitignore.
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+	".terraform":   true,
+	".next":        true,
+	".nuxt":        true,
+	".svelte-kit":  true,
+	"target":       true,
+	"dist":         true,
+	".claude":      true,
+}
+
+// maxWalkDepth bounds recursive detection in very deep trees.
+const maxWalkDepth = 6
+
+// ignoreRule is a single .gitignore pattern.
+type ignoreRule struct {
+	base     string // directory (slash-separated, relative to root) holding the .gitignore
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher evaluates .gitignore rules collected while walking.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// load reads the .gitignore in dir (relative to root, "." for the root).
+func (m *ignoreMatcher) load(root, rel string) {
+	f, err := os.Open(filepath.Join(root, rel, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	base := filepath.ToSlash(rel)
+	if base == "." {
+		base = ""
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.HasPrefix(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		} else if strings.Contains(line, "/") {
+			// A slash in the middle also anchors the pattern
+			r.anchored = true
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		m.rules = append(m.rules, r)
+	}
+}
+
+// ignored reports whether rel (slash-separated, relative to root) is ignored.
+// The last matching rule wins, as in git.
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		p := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			p = strings.TrimPrefix(rel, r.base+"/")
+		}
+		if r.matches(p) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(p string) bool {
+	if r.anchored {
+		return globMatch(r.pattern, p)
+	}
+	// Unanchored patterns match the basename at any depth
+	return globMatch(r.pattern, path.Base(p))
+}
+
+// globMatch matches slash-separated paths, supporting "**" segments.
+func globMatch(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			if len(pat) == 1 {
+				// A trailing "**" matches everything inside, not the directory itself
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// WalkDirs calls fn for every directory under root (relative, slash-separated,
+// "." for the root) that is not skipped or gitignored, up to maxWalkDepth.
+// Dependency, build and VCS directories such as node_modules, vendor and .git
+// are always skipped.
+func WalkDirs(root string, fn func(rel string)) {
+	m := &ignoreMatcher{}
+	var walk func(rel string, depth int)
+	walk = func(rel string, depth int) {
+		m.load(root, rel)
+		fn(rel)
+		if depth >= maxWalkDepth {
+			return
+		}
+
+		entries, err := os.ReadDir(filepath.Join(root, rel))
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			if !e.IsDir() || skipDirs[e.Name()] {
+				continue
+			}
+			child := e.Name()
+			if rel != "." {
+				child = rel + "/" + e.Name()
+			}
+			if m.ignored(child, true) {
+				continue
+			}
+			walk(child, depth+1)
+		}
+	}
+	walk(".", 0)
+}
+
+// IsSkippedDir reports whether a directory name is always excluded from
+// detection, e.g. node_modules or .git.
+func IsSkippedDir(name string) bool {
+	return skipDirs[name]
+}
@@ -0,0 +1,81 @@
+package stack
+
+import (
+	"path"
+	"strings"
+)
+
+// workspaceFiles declare monorepo members (see DetectWorkspaces).
+var workspaceFiles = []string{"pnpm-workspace.yaml", "go.work", "lerna.json", "nx.json", "project.json", "turbo.json"}
+
+// IsDetectionInput reports whether a change to rel (slash-separated,
+// relative to the project root) can change what detection finds:
+// manifests, lockfiles, workspace and tool configs, service configs,
+// Terraform and Helm files, .gitignore files, and the file and directory
+// markers of built-in and user-defined stack definitions. Source files are
+// not inputs.
+func IsDetectionInput(rel string) bool {
+	globs, dirs := detectionInputs()
+	segs := strings.Split(rel, "/")
+	// Markers are relative to a package directory, which may be any parent
+	for i := range segs {
+		sub := strings.Join(segs[i:], "/")
+		for _, g := range globs {
+			if ok, _ := path.Match(g, sub); ok {
+				return true
+			}
+		}
+		for _, d := range dirs {
+			// The marker directory itself or one of its parents
+			if sub == d || strings.HasPrefix(d, sub+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detectionInputs lists the file globs and directory markers detection
+// looks at, relative to a package directory.
+func detectionInputs() (globs, dirs []string) {
+	for f := range depFileMap {
+		globs = append(globs, f)
+	}
+	for f := range manifestParsers {
+		globs = append(globs, f)
+	}
+	for _, r := range lockfileReaders {
+		globs = append(globs, r.File)
+	}
+	globs = append(globs, workspaceFiles...)
+	globs = append(globs, ".gitignore", "*.tf", "Chart.yaml", "values*.yaml")
+	globs = append(globs, composeFiles...)
+	for _, f := range toolingFiles {
+		globs = append(globs, f.Glob)
+	}
+	for _, rules := range [][]contentRule{toolingContentRules, ormRules, envRules} {
+		for _, r := range rules {
+			globs = append(globs, r.Globs...)
+		}
+	}
+	for _, ci := range ciFiles {
+		globs = append(globs, ci.Path)
+		dirs = append(dirs, ci.Path)
+	}
+
+	for sub := range directoryDetectors {
+		dirs = append(dirs, sub)
+	}
+	dirs = append(dirs, helmDirs...)
+	for _, sub := range iacDirs {
+		if sub != "." {
+			dirs = append(dirs, sub)
+		}
+	}
+
+	for _, def := range Definitions() {
+		globs = append(globs, def.Match.Files...)
+		dirs = append(dirs, def.Match.Directories...)
+	}
+	return globs, dirs
+}
@@ -0,0 +1,131 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// globCandidate is a glob a rule should apply to for a given technology.
+// Globs rooted in a directory (e.g. "internal/api/**") are only used when
+// that directory exists in the project; extension-only globs (e.g.
+// "**/*_test.go") are used whenever the technology is detected.
+type globCandidate struct {
+	Tech string
+	Glob string
+}
+
+// ruleGlobs maps rule names to per-stack glob candidates.
+var ruleGlobs = map[string][]globCandidate{
+	"code-style": {
+		{"go", "cmd/**"}, {"go", "internal/**"}, {"go", "pkg/**"},
+		{"node", "src/**"}, {"node", "lib/**"}, {"node", "app/**"},
+		{"python", "src/**"}, {"django", "apps/**"}, {"python", "app/**"},
+		{"rails", "app/**"}, {"rails", "lib/**"},
+		{"rust", "src/**"}, {"java", "src/main/**"}, {"kotlin", "src/main/**"},
+		{"php", "src/**"}, {"laravel", "app/**"},
+	},
+	"testing": {
+		{"go", "**/*_test.go"},
+		{"node", "**/*.test.*"}, {"node", "**/*.spec.*"}, {"node", "__tests__/**"}, {"node", "tests/**"},
+		{"python", "tests/**"}, {"python", "**/test_*.py"}, {"python", "**/*_test.py"},
+		{"ruby", "spec/**"}, {"ruby", "test/**"},
+		{"rust", "tests/**"}, {"java", "src/test/**"}, {"kotlin", "src/test/**"},
+		{"php", "tests/**"},
+	},
+	"api": {
+		{"go", "internal/api/**"}, {"go", "internal/http/**"}, {"go", "internal/handler/**"},
+		{"go", "internal/handlers/**"}, {"go", "internal/server/**"}, {"go", "api/**"},
+		{"express", "src/routes/**"}, {"express", "routes/**"}, {"fastify", "src/routes/**"},
+		{"hono", "src/routes/**"}, {"nestjs", "src/**/*.controller.ts"},
+		{"nextjs", "app/api/**"}, {"nextjs", "pages/api/**"}, {"nextjs", "src/app/api/**"},
+		{"django", "apps/**/views.py"}, {"django", "apps/**/urls.py"}, {"django", "apps/**/serializers.py"},
+		{"django", "**/views.py"}, {"django", "**/urls.py"},
+		{"fastapi", "app/routers/**"}, {"fastapi", "app/api/**"}, {"flask", "app/routes/**"},
+		{"rails", "app/controllers/**"}, {"rails", "config/routes.rb"},
+		{"laravel", "app/Http/**"}, {"laravel", "routes/**"},
+	},
+	"frontend": {
+		{"react", "**/*.tsx"}, {"react", "**/*.jsx"}, {"react", "src/components/**"}, {"react", "components/**"},
+		{"nextjs", "app/**/*.tsx"}, {"nextjs", "components/**"},
+		{"vue", "**/*.vue"}, {"nuxt", "pages/**"}, {"nuxt", "components/**"},
+		{"svelte", "**/*.svelte"}, {"svelte", "src/routes/**"},
+		{"angular", "src/app/**"},
+		{"django", "templates/**"}, {"rails", "app/views/**"}, {"laravel", "resources/views/**"},
+	},
+	"infrastructure": {
+		{"terraform", "**/*.tf"}, {"terraform", "infra/**"},
+		{"docker", "**/Dockerfile*"}, {"docker-compose", "docker-compose*.y*ml"},
+		{"kubernetes", "k8s/**"}, {"kubernetes", "deploy/**"}, {"kubernetes", "manifests/**"},
+		{"helm", "helm/**"}, {"helm", "charts/**"},
+		{"infrastructure", "infra/**"},
+	},
+	"finops": {
+		{"terraform", "**/*.tf"}, {"terraform", "infra/**"},
+		{"kubernetes", "k8s/**"}, {"helm", "helm/**"}, {"helm", "charts/**"},
+	},
+	"documentation": {
+		{"", "docs/**"}, {"", "**/*.md"},
+	},
+}
+
+// RuleGlobs returns the globs a rule should apply to in this project, based
+// on the detected technologies and the directories that actually exist.
+// Returns nil when the rule has no mapping or nothing applies, in which case
+// the rule's shipped globs should be kept.
+func RuleGlobs(projectRoot, rule string, techs []Tech) []string {
+	candidates, ok := ruleGlobs[rule]
+	if !ok {
+		return nil
+	}
+
+	detected := make(map[string]bool, len(techs))
+	for _, t := range techs {
+		detected[t.Name] = true
+	}
+
+	seen := make(map[string]bool)
+	var globs []string
+	for _, c := range candidates {
+		if c.Tech != "" && !detected[c.Tech] {
+			continue
+		}
+		if seen[c.Glob] || !globApplies(projectRoot, c.Glob) {
+			continue
+		}
+		seen[c.Glob] = true
+		globs = append(globs, c.Glob)
+	}
+	return globs
+}
+
+// globApplies reports whether a glob can match anything in the project:
+// literal paths must exist, and directory-rooted globs need their fixed
+// directory prefix. Extension-only globs such as "**/*.go" always apply.
+func globApplies(projectRoot, glob string) bool {
+	if !strings.ContainsAny(glob, "*?[") {
+		_, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(glob)))
+		return err == nil
+	}
+
+	parts := strings.Split(glob, "/")
+	var prefix []string
+	for _, p := range parts[:len(parts)-1] {
+		if strings.ContainsAny(p, "*?[") {
+			break
+		}
+		prefix = append(prefix, p)
+	}
+
+	if len(prefix) == 0 {
+		// Top-level file patterns such as "docker-compose*.y*ml"
+		if len(parts) == 1 {
+			matches, _ := filepath.Glob(filepath.Join(projectRoot, glob))
+			return len(matches) > 0
+		}
+		return true
+	}
+
+	info, err := os.Stat(filepath.Join(projectRoot, filepath.Join(prefix...)))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,283 @@
+package stack
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lockIndex maps dependency names to resolved versions. Entries keyed
+// "name@range" record which version a specific range resolved to when a
+// lockfile holds several versions of the same package; plain "name" keys
+// hold the version used by the package itself, or the highest one seen.
+type lockIndex map[string]string
+
+// set records a version for name, keeping the highest when name repeats.
+func (l lockIndex) set(name, version string) {
+	if version == "" {
+		return
+	}
+	if cur, ok := l[name]; !ok || CompareVersions(version, cur) > 0 {
+		l[name] = version
+	}
+}
+
+// lookup returns the resolved version for a declared dependency.
+func (l lockIndex) lookup(d Dependency) string {
+	if v, ok := l[d.Name+"@"+d.Constraint]; ok {
+		return v
+	}
+	return l[d.Name]
+}
+
+// lockfileReaders read lockfiles per ecosystem, in preference order.
+// importer is the package directory relative to the lockfile's directory
+// ("." when they are the same), used by lockfiles that cover a workspace.
+var lockfileReaders = []struct {
+	Ecosystem string
+	File      string
+	Read      func(data []byte, importer string) lockIndex
+}{
+	{EcosystemNPM, "package-lock.json", readPackageLock},
+	{EcosystemNPM, "pnpm-lock.yaml", readPnpmLock},
+	{EcosystemNPM, "yarn.lock", readYarnLock},
+	{EcosystemPyPI, "poetry.lock", readTOMLPackages},
+	{EcosystemGo, "go.sum", readGoSum},
+	{EcosystemCargo, "Cargo.lock", readTOMLPackages},
+	{EcosystemRubyGems, "Gemfile.lock", readGemfileLock},
+	{EcosystemComposer, "composer.lock", readComposerLock},
+}
+
+// lockedVersions reads the nearest lockfile of each ecosystem for a package,
+// looking in the package directory first and then in its parents up to the
+// project root, since workspaces usually keep a single lockfile at the root.
+func lockedVersions(projectRoot, rel string) map[string]lockIndex {
+	indexes := make(map[string]lockIndex)
+	for dir := rel; ; dir = path.Dir(dir) {
+		for _, r := range lockfileReaders {
+			if _, done := indexes[r.Ecosystem]; done {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(dir), r.File))
+			if err != nil {
+				continue
+			}
+			importer := rel
+			if dir != "." {
+				importer = strings.TrimPrefix(strings.TrimPrefix(rel, dir), "/")
+			}
+			if importer == "" {
+				importer = "."
+			}
+
+			// Lockfiles may spell names differently from manifests
+			idx := lockIndex{}
+			for key, v := range r.Read(data, importer) {
+				if at := strings.LastIndex(key, "@"); at > 0 {
+					idx[normalizeDepName(r.Ecosystem, key[:at])+key[at:]] = v
+				} else {
+					idx[normalizeDepName(r.Ecosystem, key)] = v
+				}
+			}
+			indexes[r.Ecosystem] = idx
+		}
+		if dir == "." {
+			break
+		}
+	}
+	return indexes
+}
+
+// --- npm ---
+
+func readPackageLock(data []byte, importer string) lockIndex {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return nil
+	}
+
+	idx := lockIndex{}
+	// lockfileVersion 1
+	for name, p := range lock.Dependencies {
+		idx.set(name, p.Version)
+	}
+	// lockfileVersion 2+: hoisted "node_modules/x" entries, overridden by
+	// copies nested in the importer's own node_modules
+	nested := make(map[string]string)
+	for key, p := range lock.Packages {
+		i := strings.LastIndex(key, "node_modules/")
+		if i < 0 {
+			continue
+		}
+		name := key[i+len("node_modules/"):]
+		switch prefix := strings.TrimSuffix(key[:i], "/"); prefix {
+		case "":
+			idx[name] = p.Version
+		case importer:
+			nested[name] = p.Version
+		}
+	}
+	for name, v := range nested {
+		idx[name] = v
+	}
+	return idx
+}
+
+// pnpmVersion strips peer-dependency suffixes: "14.1.0(react@18.2.0)" and
+// the v5 form "14.1.0_react@18.2.0" both resolve to "14.1.0".
+func pnpmVersion(v string) string {
+	if i := strings.IndexAny(v, "(_"); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+func readPnpmLock(data []byte, importer string) lockIndex {
+	// Values are a version string (v5) or {specifier, version}. Plain maps
+	// are used because yaml.v3 decodes nested mappings into the field's type.
+	type section struct {
+		Dependencies         map[string]any `yaml:"dependencies"`
+		DevDependencies      map[string]any `yaml:"devDependencies"`
+		OptionalDependencies map[string]any `yaml:"optionalDependencies"`
+	}
+	var lock struct {
+		section   `yaml:",inline"`
+		Importers map[string]section `yaml:"importers"`
+	}
+	if yaml.Unmarshal(data, &lock) != nil {
+		return nil
+	}
+
+	s := lock.section
+	if imp, ok := lock.Importers[importer]; ok {
+		s = imp
+	}
+
+	idx := lockIndex{}
+	for _, m := range []map[string]any{s.Dependencies, s.DevDependencies, s.OptionalDependencies} {
+		for name, v := range m {
+			switch val := v.(type) {
+			case string:
+				idx[name] = pnpmVersion(val)
+			case map[string]any:
+				if ver, ok := val["version"].(string); ok {
+					idx[name] = pnpmVersion(ver)
+				}
+			}
+		}
+	}
+	return idx
+}
+
+var yarnVersion = regexp.MustCompile(`^\s+version:?\s+"?([^"\s]+)"?`)
+
+// readYarnLock handles both the v1 and Berry formats. Each entry header lists
+// the "name@range" descriptors that resolved to the version below it.
+func readYarnLock(data []byte, _ string) lockIndex {
+	idx := lockIndex{}
+	var descriptors []string
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			descriptors = descriptors[:0]
+			for _, d := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				descriptors = append(descriptors, strings.Trim(strings.TrimSpace(d), `"`))
+			}
+			continue
+		}
+		m := yarnVersion.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for _, d := range descriptors {
+			// The name may itself start with "@" for scoped packages
+			at := strings.LastIndex(d, "@")
+			if at <= 0 {
+				continue
+			}
+			name, rng := d[:at], strings.TrimPrefix(d[at+1:], "npm:")
+			idx[name+"@"+rng] = m[1]
+			idx.set(name, m[1])
+		}
+	}
+	return idx
+}
+
+// --- poetry.lock, Cargo.lock ---
+
+func readTOMLPackages(data []byte, _ string) lockIndex {
+	idx := lockIndex{}
+	for _, p := range parseTOML(string(data)).Arrays["package"] {
+		idx.set(p.str("name"), p.str("version"))
+	}
+	return idx
+}
+
+// --- Go ---
+
+// readGoSum records every module version listed. go.mod already names the
+// selected version, so lookups hit the "module@version" keys directly.
+func readGoSum(data []byte, _ string) lockIndex {
+	idx := lockIndex{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		version := strings.TrimSuffix(fields[1], "/go.mod")
+		idx[fields[0]+"@"+version] = version
+		idx.set(fields[0], version)
+	}
+	return idx
+}
+
+// --- Ruby ---
+
+var gemLockSpec = regexp.MustCompile(`^    ([A-Za-z0-9_.-]+) \(([^)]+)\)$`)
+
+func readGemfileLock(data []byte, _ string) lockIndex {
+	idx := lockIndex{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := gemLockSpec.FindStringSubmatch(line); m != nil {
+			// Platform-specific gems carry a suffix: "nokogiri (1.16.0-x86_64-linux)"
+			v, _, _ := strings.Cut(m[2], "-")
+			idx.set(m[1], v)
+		}
+	}
+	return idx
+}
+
+// --- PHP ---
+
+func readComposerLock(data []byte, _ string) lockIndex {
+	var lock struct {
+		Packages    []struct{ Name, Version string } `json:"packages"`
+		PackagesDev []struct{ Name, Version string } `json:"packages-dev"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return nil
+	}
+	idx := lockIndex{}
+	for _, p := range append(lock.Packages, lock.PackagesDev...) {
+		idx.set(p.Name, strings.TrimPrefix(p.Version, "v"))
+	}
+	return idx
+}
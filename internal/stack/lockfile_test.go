@@ -0,0 +1,232 @@
+package stack
+
+import "testing"
+
+func TestLockedVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		rel       string
+		ecosystem string
+		deps      map[Dependency]string // dependency → locked version
+	}{
+		{
+			name: "package-lock v3",
+			files: map[string]string{
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {
+  "": {"name": "root"},
+  "node_modules/next": {"version": "12.3.4"},
+  "node_modules/react": {"version": "18.2.0"},
+  "node_modules/next/node_modules/react": {"version": "17.0.2"},
+  "apps/web/node_modules/react": {"version": "18.3.1"}
+}}`,
+			},
+			rel:       "apps/web",
+			ecosystem: EcosystemNPM,
+			deps: map[Dependency]string{
+				{Name: "next", Constraint: "^12.3.0"}: "12.3.4",
+				{Name: "react", Constraint: "^18"}:    "18.3.1",
+				{Name: "vue", Constraint: "^3"}:       "",
+			},
+		},
+		{
+			name: "package-lock v1",
+			files: map[string]string{
+				"package-lock.json": `{"lockfileVersion": 1, "dependencies": {"express": {"version": "4.18.2"}}}`,
+			},
+			rel:       ".",
+			ecosystem: EcosystemNPM,
+			deps:      map[Dependency]string{{Name: "express", Constraint: "^4"}: "4.18.2"},
+		},
+		{
+			name: "pnpm-lock importers",
+			files: map[string]string{
+				"pnpm-lock.yaml": `lockfileVersion: '6.0'
+importers:
+  .:
+    devDependencies:
+      typescript:
+        specifier: ^5.3.0
+        version: 5.3.3
+  apps/web:
+    dependencies:
+      next:
+        specifier: ^14.1.0
+        version: 14.1.0(react@18.2.0)
+`,
+			},
+			rel:       "apps/web",
+			ecosystem: EcosystemNPM,
+			deps: map[Dependency]string{
+				{Name: "next", Constraint: "^14.1.0"}:  "14.1.0",
+				{Name: "typescript", Constraint: "^5"}: "",
+			},
+		},
+		{
+			name: "pnpm-lock v5",
+			files: map[string]string{
+				"pnpm-lock.yaml": "lockfileVersion: 5.4\ndependencies:\n  next: 13.0.0_react@18.2.0\n",
+			},
+			rel:       ".",
+			ecosystem: EcosystemNPM,
+			deps:      map[Dependency]string{{Name: "next", Constraint: "^13"}: "13.0.0"},
+		},
+		{
+			name: "yarn v1",
+			files: map[string]string{
+				"yarn.lock": `# yarn lockfile v1
+
+"@babel/core@^7.0.0", "@babel/core@^7.23.0":
+  version "7.23.7"
+
+react@^17.0.2:
+  version "17.0.2"
+
+react@^18.0.0:
+  version "18.2.0"
+`,
+			},
+			rel:       ".",
+			ecosystem: EcosystemNPM,
+			deps: map[Dependency]string{
+				{Name: "react", Constraint: "^17.0.2"}:      "17.0.2",
+				{Name: "react", Constraint: "^18.0.0"}:      "18.2.0",
+				{Name: "react", Constraint: "*"}:            "18.2.0",
+				{Name: "@babel/core", Constraint: "^7.0.0"}: "7.23.7",
+			},
+		},
+		{
+			name: "yarn berry",
+			files: map[string]string{
+				"yarn.lock": "__metadata:\n  version: 6\n\n\"vue@npm:^3.4.0\":\n  version: 3.4.15\n  resolution: \"vue@npm:3.4.15\"\n",
+			},
+			rel:       ".",
+			ecosystem: EcosystemNPM,
+			deps:      map[Dependency]string{{Name: "vue", Constraint: "^3.4.0"}: "3.4.15"},
+		},
+		{
+			name: "poetry.lock",
+			files: map[string]string{
+				"services/api/poetry.lock": "[[package]]\nname = \"Django\"\nversion = \"4.2.7\"\n\n[[package]]\nname = \"django_ninja\"\nversion = \"1.1.0\"\n",
+			},
+			rel:       "services/api",
+			ecosystem: EcosystemPyPI,
+			deps: map[Dependency]string{
+				{Name: "django"}:       "4.2.7",
+				{Name: "django-ninja"}: "1.1.0",
+			},
+		},
+		{
+			name: "Cargo.lock",
+			files: map[string]string{
+				"Cargo.lock": "version = 3\n\n[[package]]\nname = \"axum\"\nversion = \"0.6.20\"\n\n[[package]]\nname = \"axum\"\nversion = \"0.7.4\"\n",
+			},
+			rel:       "crates/web",
+			ecosystem: EcosystemCargo,
+			deps:      map[Dependency]string{{Name: "axum", Constraint: "0.7"}: "0.7.4"},
+		},
+		{
+			name: "go.sum",
+			files: map[string]string{
+				"go.sum": "github.com/gin-gonic/gin v1.9.0 h1:a=\ngithub.com/gin-gonic/gin v1.9.0/go.mod h1:b=\ngithub.com/gin-gonic/gin v1.9.1 h1:c=\n",
+			},
+			rel:       ".",
+			ecosystem: EcosystemGo,
+			deps: map[Dependency]string{
+				{Name: "github.com/gin-gonic/gin", Constraint: "v1.9.0"}: "v1.9.0",
+				{Name: "github.com/gin-gonic/gin"}:                       "v1.9.1",
+			},
+		},
+		{
+			name: "Gemfile.lock",
+			files: map[string]string{
+				"Gemfile.lock": "GEM\n  remote: https://rubygems.org/\n  specs:\n    Rails (7.1.2)\n      actionpack (= 7.1.2)\n    nokogiri (1.16.0-x86_64-linux)\n",
+			},
+			rel:       ".",
+			ecosystem: EcosystemRubyGems,
+			deps: map[Dependency]string{
+				{Name: "rails"}:      "7.1.2",
+				{Name: "nokogiri"}:   "1.16.0",
+				{Name: "actionpack"}: "",
+			},
+		},
+		{
+			name: "composer.lock",
+			files: map[string]string{
+				"composer.lock": `{"packages": [{"name": "Laravel/Framework", "version": "v10.40.0"}], "packages-dev": [{"name": "phpunit/phpunit", "version": "10.5.5"}]}`,
+			},
+			rel:       ".",
+			ecosystem: EcosystemComposer,
+			deps: map[Dependency]string{
+				{Name: "laravel/framework"}: "10.40.0",
+				{Name: "phpunit/phpunit"}:   "10.5.5",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := lockedVersions(writeTree(t, tt.files), tt.rel)
+			for dep, want := range tt.deps {
+				if got := locks[tt.ecosystem].lookup(dep); got != want {
+					t.Errorf("lookup(%s %s) = %q, want %q", dep.Name, dep.Constraint, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLockedVersionsNearestLockfile(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"package-lock.json":          `{"packages": {"node_modules/next": {"version": "13.5.6"}}}`,
+		"apps/web/package-lock.json": `{"packages": {"node_modules/next": {"version": "14.1.0"}}}`,
+	})
+	if got := lockedVersions(root, "apps/web")[EcosystemNPM].lookup(Dependency{Name: "next"}); got != "14.1.0" {
+		t.Errorf("apps/web next = %q, want the package's own lockfile", got)
+	}
+	if got := lockedVersions(root, "apps/docs")[EcosystemNPM].lookup(Dependency{Name: "next"}); got != "13.5.6" {
+		t.Errorf("apps/docs next = %q, want the root lockfile", got)
+	}
+}
+
+func TestDetectLockedVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "locked",
+			files: map[string]string{
+				"package.json":      `{"dependencies": {"next": "^12.3.0"}}`,
+				"package-lock.json": `{"packages": {"node_modules/next": {"version": "12.3.4"}}}`,
+			},
+			want: "12.3.4",
+		},
+		{
+			name:  "constraint floor without a lockfile",
+			files: map[string]string{"package.json": `{"dependencies": {"next": "^12.3.0"}}`},
+			want:  "12.3.0",
+		},
+		{
+			name: "go.mod version",
+			files: map[string]string{
+				"go.mod": "module m\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+				"go.sum": "github.com/gin-gonic/gin v1.9.1 h1:c=\ngithub.com/gin-gonic/gin v1.10.0 h1:d=\n",
+			},
+			want: "1.9.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, tech := range DetectStack(writeTree(t, tt.files)) {
+				if tech.Name == "nextjs" || tech.Name == "gin" {
+					if tech.Version != tt.want {
+						t.Errorf("%s version = %q, want %q", tech.Name, tech.Version, tt.want)
+					}
+					return
+				}
+			}
+			t.Error("framework not detected")
+		})
+	}
+}
@@ -0,0 +1,440 @@
+package stack
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ecosystems a dependency can belong to.
+const (
+	EcosystemNPM      = "npm"
+	EcosystemPyPI     = "pypi"
+	EcosystemRubyGems = "rubygems"
+	EcosystemComposer = "composer"
+	EcosystemGo       = "go"
+	EcosystemCargo    = "cargo"
+	EcosystemMaven    = "maven"
+)
+
+// Dependency is a package declared in a manifest.
+type Dependency struct {
+	Ecosystem  string
+	Name       string // normalised package name, e.g. "@nestjs/core", "flask", "org.springframework.boot:spring-boot-starter-web"
+	Constraint string // version constraint as written, e.g. "^14.1.0", ">=2.0,<3"; empty if unconstrained
+	Scope      string // "prod", "dev", "peer", "optional", "test", "build", "indirect" or a group name
+	Manifest   string // manifest file name, e.g. "package.json"
+}
+
+// manifestParsers maps manifest file names to their parsers.
+var manifestParsers = map[string]struct {
+	Ecosystem string
+	Parse     func(data []byte) []Dependency
+}{
+	"package.json":     {EcosystemNPM, parsePackageJSON},
+	"requirements.txt": {EcosystemPyPI, parseRequirements},
+	"pyproject.toml":   {EcosystemPyPI, parsePyproject},
+	"Pipfile":          {EcosystemPyPI, parsePipfile},
+	"Gemfile":          {EcosystemRubyGems, parseGemfile},
+	"composer.json":    {EcosystemComposer, parseComposerJSON},
+	"go.mod":           {EcosystemGo, parseGoMod},
+	"Cargo.toml":       {EcosystemCargo, parseCargoToml},
+	"pom.xml":          {EcosystemMaven, parsePomXML},
+}
+
+// ParseManifest parses a dependency manifest. The parser is chosen by file
+// name; unknown files yield nil.
+func ParseManifest(path string) ([]Dependency, error) {
+	name := filepath.Base(path)
+	p, ok := manifestParsers[name]
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	deps := p.Parse(data)
+	for i := range deps {
+		deps[i].Ecosystem = p.Ecosystem
+		deps[i].Manifest = name
+		deps[i].Name = normalizeDepName(p.Ecosystem, deps[i].Name)
+	}
+	return deps, nil
+}
+
+// DirDependencies parses every known manifest in dir, in a stable order.
+func DirDependencies(dir string) []Dependency {
+	names := make([]string, 0, len(manifestParsers))
+	for name := range manifestParsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var deps []Dependency
+	for _, name := range names {
+		parsed, err := ParseManifest(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		deps = append(deps, parsed...)
+	}
+	return deps
+}
+
+// normalizeDepName applies each ecosystem's name-equivalence rules
+// (PEP 503 for Python, case-insensitive gems and Composer packages).
+func normalizeDepName(ecosystem, name string) string {
+	name = strings.TrimSpace(name)
+	switch ecosystem {
+	case EcosystemPyPI:
+		return pep503Separators.ReplaceAllString(strings.ToLower(name), "-")
+	case EcosystemRubyGems, EcosystemComposer:
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+var pep503Separators = regexp.MustCompile(`[-_.]+`)
+
+// --- npm ---
+
+func parsePackageJSON(data []byte) []Dependency {
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+
+	var deps []Dependency
+	deps = appendDepMap(deps, pkg.Dependencies, "prod")
+	deps = appendDepMap(deps, pkg.DevDependencies, "dev")
+	deps = appendDepMap(deps, pkg.PeerDependencies, "peer")
+	deps = appendDepMap(deps, pkg.OptionalDependencies, "optional")
+	return deps
+}
+
+// appendDepMap appends name → constraint entries in name order.
+func appendDepMap(deps []Dependency, m map[string]string, scope string) []Dependency {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		deps = append(deps, Dependency{Name: name, Constraint: m[name], Scope: scope})
+	}
+	return deps
+}
+
+// --- Python ---
+
+// pep508 matches "name[extras] constraint ; marker" requirement strings.
+var pep508 = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:\(([^)]*)\)|([^;@]*))`)
+
+// pep508URL matches PEP 508 direct references, "name[extras] @ url".
+var pep508URL = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*\s*(?:\[[^\]]*\])?\s*@`)
+
+// parsePEP508 parses a single requirement specifier. Direct URL references
+// ("name @ https://...") keep the name with no constraint.
+func parsePEP508(spec string) (Dependency, bool) {
+	spec = strings.TrimSpace(spec)
+	m := pep508.FindStringSubmatch(spec)
+	if m == nil {
+		return Dependency{}, false
+	}
+	constraint := m[2]
+	if constraint == "" {
+		constraint = m[3]
+	}
+	return Dependency{Name: m[1], Constraint: strings.TrimSpace(constraint)}, true
+}
+
+func parseRequirements(data []byte) []Dependency {
+	var deps []Dependency
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Inline comments need preceding whitespace per pip's syntax
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if rest, ok := strings.CutPrefix(line, "-e "); ok {
+			line = strings.TrimSpace(rest)
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if strings.Contains(line, "://") && !pep508URL.MatchString(line) {
+			// VCS or archive URL: only "#egg=name" says what it installs
+			if _, egg, ok := strings.Cut(line, "#egg="); ok {
+				if name, _, _ := strings.Cut(egg, "&"); name != "" {
+					deps = append(deps, Dependency{Name: name, Scope: "prod"})
+				}
+			}
+			continue
+		}
+		if d, ok := parsePEP508(line); ok {
+			d.Scope = "prod"
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+func parsePyproject(data []byte) []Dependency {
+	doc := parseTOML(string(data))
+	var deps []Dependency
+
+	// PEP 621
+	if project, ok := doc.Tables["project"]; ok {
+		for _, spec := range project.list("dependencies") {
+			if d, ok := parsePEP508(spec); ok {
+				d.Scope = "prod"
+				deps = append(deps, d)
+			}
+		}
+	}
+	if extras, ok := doc.Tables["project.optional-dependencies"]; ok {
+		for _, group := range sortedTableKeys(extras) {
+			for _, spec := range extras.list(group) {
+				if d, ok := parsePEP508(spec); ok {
+					d.Scope = group
+					deps = append(deps, d)
+				}
+			}
+		}
+	}
+
+	// Poetry
+	deps = appendTOMLDeps(deps, doc.Tables["tool.poetry.dependencies"], "prod")
+	deps = appendTOMLDeps(deps, doc.Tables["tool.poetry.dev-dependencies"], "dev")
+	for name, table := range doc.Tables {
+		if group, ok := strings.CutPrefix(name, "tool.poetry.group."); ok {
+			if group, ok = strings.CutSuffix(group, ".dependencies"); ok {
+				deps = appendTOMLDeps(deps, table, group)
+			}
+		}
+	}
+
+	return deps
+}
+
+func parsePipfile(data []byte) []Dependency {
+	doc := parseTOML(string(data))
+	var deps []Dependency
+	deps = appendTOMLDeps(deps, doc.Tables["packages"], "prod")
+	deps = appendTOMLDeps(deps, doc.Tables["dev-packages"], "dev")
+	return deps
+}
+
+// appendTOMLDeps reads a `name = "constraint"` or `name = { version = ... }`
+// table (Poetry, Pipfile, Cargo). The "python" entry is not a package.
+func appendTOMLDeps(deps []Dependency, table tomlTable, scope string) []Dependency {
+	for _, name := range sortedTableKeys(table) {
+		if name == "python" {
+			continue
+		}
+		var constraint string
+		switch v := table[name].(type) {
+		case string:
+			constraint = v
+		case tomlTable:
+			constraint = v.str("version")
+		}
+		if constraint == "*" {
+			constraint = ""
+		}
+		deps = append(deps, Dependency{Name: name, Constraint: constraint, Scope: scope})
+	}
+	return deps
+}
+
+func sortedTableKeys(t tomlTable) []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// --- Ruby ---
+
+var (
+	gemLine   = regexp.MustCompile(`^gem\s+['"]([^'"]+)['"]((?:\s*,\s*['"][^'"]*['"])*)`)
+	gemArg    = regexp.MustCompile(`['"]([^'"]*)['"]`)
+	groupLine = regexp.MustCompile(`^group\s+(.+?)\s+do\b`)
+)
+
+func parseGemfile(data []byte) []Dependency {
+	var deps []Dependency
+	var groups []string // stack of open group blocks; "" for other blocks
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripLineComment(scanner.Text(), "#"))
+		switch {
+		case line == "":
+		case groupLine.MatchString(line):
+			names := groupLine.FindStringSubmatch(line)[1]
+			groups = append(groups, strings.ReplaceAll(strings.ReplaceAll(names, ":", ""), " ", ""))
+		case strings.HasSuffix(line, " do") || strings.Contains(line, " do |"):
+			groups = append(groups, "")
+		case line == "end":
+			if len(groups) > 0 {
+				groups = groups[:len(groups)-1]
+			}
+		default:
+			m := gemLine.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			var constraints []string
+			for _, a := range gemArg.FindAllStringSubmatch(m[2], -1) {
+				constraints = append(constraints, a[1])
+			}
+			scope := "prod"
+			for i := len(groups) - 1; i >= 0; i-- {
+				if groups[i] != "" {
+					scope = groups[i]
+					break
+				}
+			}
+			deps = append(deps, Dependency{Name: m[1], Constraint: strings.Join(constraints, ", "), Scope: scope})
+		}
+	}
+	return deps
+}
+
+// --- PHP ---
+
+func parseComposerJSON(data []byte) []Dependency {
+	var c struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if json.Unmarshal(data, &c) != nil {
+		return nil
+	}
+
+	var deps []Dependency
+	for _, d := range appendDepMap(nil, c.Require, "prod") {
+		if isComposerPackage(d.Name) {
+			deps = append(deps, d)
+		}
+	}
+	for _, d := range appendDepMap(nil, c.RequireDev, "dev") {
+		if isComposerPackage(d.Name) {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// isComposerPackage filters platform requirements such as "php" and "ext-json".
+func isComposerPackage(name string) bool {
+	return strings.Contains(name, "/")
+}
+
+// --- Go ---
+
+func parseGoMod(data []byte) []Dependency {
+	var deps []Dependency
+	inBlock := false
+
+	add := func(spec string) {
+		scope := "prod"
+		if strings.Contains(spec, "// indirect") {
+			scope = "indirect"
+		}
+		fields := strings.Fields(stripLineComment(spec, "//"))
+		if len(fields) < 2 {
+			return
+		}
+		deps = append(deps, Dependency{Name: fields[0], Constraint: fields[1], Scope: scope})
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "require (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock:
+			add(line)
+		case strings.HasPrefix(line, "require "):
+			add(strings.TrimPrefix(line, "require "))
+		}
+	}
+	return deps
+}
+
+// --- Rust ---
+
+func parseCargoToml(data []byte) []Dependency {
+	doc := parseTOML(string(data))
+	var deps []Dependency
+	deps = appendTOMLDeps(deps, doc.Tables["dependencies"], "prod")
+	deps = appendTOMLDeps(deps, doc.Tables["dev-dependencies"], "dev")
+	deps = appendTOMLDeps(deps, doc.Tables["build-dependencies"], "build")
+	deps = appendTOMLDeps(deps, doc.Tables["workspace.dependencies"], "prod")
+	return deps
+}
+
+// --- Java ---
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+func parsePomXML(data []byte) []Dependency {
+	var pom struct {
+		Parent       pomDependency   `xml:"parent"`
+		Dependencies []pomDependency `xml:"dependencies>dependency"`
+		Managed      []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+	}
+	if xml.Unmarshal(data, &pom) != nil {
+		return nil
+	}
+
+	var deps []Dependency
+	add := func(d pomDependency, scope string) {
+		if d.ArtifactID == "" {
+			return
+		}
+		if d.Scope != "" && scope == "" {
+			scope = d.Scope
+		}
+		if scope == "" || scope == "compile" {
+			scope = "prod"
+		}
+		deps = append(deps, Dependency{
+			Name:       strings.TrimSpace(d.GroupID) + ":" + strings.TrimSpace(d.ArtifactID),
+			Constraint: strings.TrimSpace(d.Version),
+			Scope:      scope,
+		})
+	}
+
+	add(pom.Parent, "parent")
+	for _, d := range pom.Dependencies {
+		add(d, "")
+	}
+	for _, d := range pom.Managed {
+		add(d, "managed")
+	}
+	return deps
+}
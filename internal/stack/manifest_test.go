@@ -0,0 +1,257 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// writeTree creates files (slash paths to contents) in a new temporary
+// directory and returns it.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// techNames returns the names of techs, in order.
+func techNames(techs []Tech) []string {
+	names := make([]string, len(techs))
+	for i, t := range techs {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    []Dependency // Ecosystem and Manifest are filled in
+	}{
+		{
+			file: "Cargo.toml",
+			content: `[package]
+name = "api"
+
+[dependencies]
+serde = "1.0"
+tokio = { version = "1.35", features = ["full"] }
+axum.workspace = true
+
+[dependencies.actix-web]
+version = "4"
+default-features = false
+
+[dev-dependencies]
+proptest = "*"
+
+[build-dependencies]
+cc = "1"
+
+[workspace.dependencies]
+tracing = "0.1"
+`,
+			want: []Dependency{
+				{Name: "actix-web", Constraint: "4", Scope: "prod"},
+				{Name: "axum", Scope: "prod"},
+				{Name: "serde", Constraint: "1.0", Scope: "prod"},
+				{Name: "tokio", Constraint: "1.35", Scope: "prod"},
+				{Name: "proptest", Scope: "dev"},
+				{Name: "cc", Constraint: "1", Scope: "build"},
+				{Name: "tracing", Constraint: "0.1", Scope: "prod"},
+			},
+		},
+		{
+			file: "pyproject.toml",
+			content: `[tool.poetry]
+name = "svc"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+Django = "^4.2"
+celery = { version = "^5.3", extras = ["redis"] }
+
+[tool.poetry.dependencies.FastAPI]
+version = "0.110"
+optional = true
+
+[tool.poetry.group.test.dependencies]
+pytest = "^8.0"
+`,
+			want: []Dependency{
+				{Name: "django", Constraint: "^4.2", Scope: "prod"},
+				{Name: "fastapi", Constraint: "0.110", Scope: "prod"},
+				{Name: "celery", Constraint: "^5.3", Scope: "prod"},
+				{Name: "pytest", Constraint: "^8.0", Scope: "test"},
+			},
+		},
+		{
+			file: "pyproject.toml",
+			content: `[project]
+name = "svc"
+dependencies = [
+  "flask>=2.0,<3",   # web
+  "SQLAlchemy[asyncio] (>=2.0)",
+  "pkg @ https://example.com/pkg.whl",
+]
+
+[project.optional-dependencies]
+dev = ["ruff"]
+`,
+			want: []Dependency{
+				{Name: "flask", Constraint: ">=2.0,<3", Scope: "prod"},
+				{Name: "sqlalchemy", Constraint: ">=2.0", Scope: "prod"},
+				{Name: "pkg", Scope: "prod"},
+				{Name: "ruff", Scope: "dev"},
+			},
+		},
+		{
+			file: "package.json",
+			content: `{
+  "dependencies": {"next": "^14.1.0", "react": "18.2.0"},
+  "devDependencies": {"typescript": "~5.3"},
+  "peerDependencies": {"react-dom": ">=18"},
+  "optionalDependencies": {"fsevents": "*"}
+}`,
+			want: []Dependency{
+				{Name: "next", Constraint: "^14.1.0", Scope: "prod"},
+				{Name: "react", Constraint: "18.2.0", Scope: "prod"},
+				{Name: "typescript", Constraint: "~5.3", Scope: "dev"},
+				{Name: "react-dom", Constraint: ">=18", Scope: "peer"},
+				{Name: "fsevents", Constraint: "*", Scope: "optional"},
+			},
+		},
+		{
+			file: "go.mod",
+			content: `module example.com/api
+
+go 1.22
+
+require github.com/gin-gonic/gin v1.9.1
+
+require (
+	github.com/labstack/echo/v4 v4.11.4 // web
+	golang.org/x/sys v0.16.0 // indirect
+)
+`,
+			want: []Dependency{
+				{Name: "github.com/gin-gonic/gin", Constraint: "v1.9.1", Scope: "prod"},
+				{Name: "github.com/labstack/echo/v4", Constraint: "v4.11.4", Scope: "prod"},
+				{Name: "golang.org/x/sys", Constraint: "v0.16.0", Scope: "indirect"},
+			},
+		},
+		{
+			file: "Gemfile",
+			content: `source "https://rubygems.org"
+
+gem "Rails", "~> 7.1", ">= 7.1.2"
+gem 'pg' # database
+
+group :development, :test do
+  gem "rspec-rails"
+end
+
+platforms :jruby do
+  gem "activerecord-jdbc-adapter"
+end
+`,
+			want: []Dependency{
+				{Name: "rails", Constraint: "~> 7.1, >= 7.1.2", Scope: "prod"},
+				{Name: "pg", Scope: "prod"},
+				{Name: "rspec-rails", Scope: "development,test"},
+				{Name: "activerecord-jdbc-adapter", Scope: "prod"},
+			},
+		},
+		{
+			file: "pom.xml",
+			content: `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.1</version>
+  </parent>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.1</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson</groupId>
+        <artifactId>jackson-bom</artifactId>
+        <version>2.16.0</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`,
+			want: []Dependency{
+				{Name: "org.springframework.boot:spring-boot-starter-parent", Constraint: "3.2.1", Scope: "parent"},
+				{Name: "org.springframework.boot:spring-boot-starter-web", Scope: "prod"},
+				{Name: "org.junit.jupiter:junit-jupiter", Constraint: "5.10.1", Scope: "test"},
+				{Name: "com.fasterxml.jackson:jackson-bom", Constraint: "2.16.0", Scope: "managed"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := writeTree(t, map[string]string{tt.file: tt.content})
+			got, err := ParseManifest(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].Ecosystem = manifestParsers[tt.file].Ecosystem
+				tt.want[i].Manifest = tt.file
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseManifestInvalid(t *testing.T) {
+	for file, content := range map[string]string{
+		"Cargo.toml":   "[dependencies\nserde = ",
+		"package.json": "{",
+		"pom.xml":      "<project>",
+	} {
+		dir := writeTree(t, map[string]string{file: content})
+		got, err := ParseManifest(filepath.Join(dir, file))
+		if err != nil || len(got) != 0 {
+			t.Errorf("%s: got %+v, %v; want nothing", file, got, err)
+		}
+	}
+}
+
+func TestDetectStackCargoDependencyTables(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"Cargo.toml": "[package]\nname = \"api\"\n\n[dependencies]\naxum.workspace = true\n\n[dependencies.actix-web]\nversion = \"4\"\n",
+	})
+	got := techNames(DetectStack(dir))
+	for _, want := range []string{"actix-web", "axum", "rust"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("DetectStack = %v, missing %s", got, want)
+		}
+	}
+}
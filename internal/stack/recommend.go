@@ -0,0 +1,48 @@
+package stack
+
+import "strings"
+
+// Recommendation is a kit component suited to the detected stack.
+type Recommendation struct {
+	Kind  string   // component kind, e.g. "skills", "agents"
+	Name  string   // component name
+	Techs []string // detected technologies that triggered it
+}
+
+// Reason describes why the component is recommended.
+func (r Recommendation) Reason() string {
+	return strings.Join(r.Techs, ", ") + " detected"
+}
+
+// componentRecommendations map technologies to kit components.
+var componentRecommendations = []struct {
+	Kind  string
+	Name  string
+	Techs []string
+}{
+	{"skills", "database-review", []string{"postgres", "mysql", "mongodb", "redis", "prisma", "drizzle"}},
+	{"agents", "finops", []string{"aws", "gcp", "azure"}},
+}
+
+// Recommend returns the components recommended for the detected stack
+// names, in table order.
+func Recommend(stackNames []string) []Recommendation {
+	detected := make(map[string]bool, len(stackNames))
+	for _, n := range stackNames {
+		detected[n] = true
+	}
+
+	var recs []Recommendation
+	for _, cr := range componentRecommendations {
+		var techs []string
+		for _, t := range cr.Techs {
+			if detected[t] {
+				techs = append(techs, t)
+			}
+		}
+		if len(techs) > 0 {
+			recs = append(recs, Recommendation{Kind: cr.Kind, Name: cr.Name, Techs: techs})
+		}
+	}
+	return recs
+}
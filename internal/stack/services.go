@@ -0,0 +1,399 @@
+package stack
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Backing services: databases, message brokers and cloud providers.
+var (
+	techPostgres = Tech{Name: "postgres", Category: "database"}
+	techMySQL    = Tech{Name: "mysql", Category: "database"}
+	techRedis    = Tech{Name: "redis", Category: "database"}
+	techMongoDB  = Tech{Name: "mongodb", Category: "database"}
+	techKafka    = Tech{Name: "kafka", Category: "messaging"}
+	techRabbitMQ = Tech{Name: "rabbitmq", Category: "messaging"}
+	techAWS      = Tech{Name: "aws", Category: "cloud"}
+	techGCP      = Tech{Name: "gcp", Category: "cloud"}
+	techAzure    = Tech{Name: "azure", Category: "cloud"}
+)
+
+// isServiceCategory reports whether a category describes a backing service.
+// Their versions are not taken from client SDK dependencies.
+func isServiceCategory(category string) bool {
+	return category == "database" || category == "messaging" || category == "cloud"
+}
+
+// serviceDependencyRules detect services from client SDKs.
+var serviceDependencyRules = []dependencyRule{
+	// npm
+	{EcosystemNPM, "pg", techPostgres}, {EcosystemNPM, "postgres", techPostgres},
+	{EcosystemNPM, "mysql", techMySQL}, {EcosystemNPM, "mysql2", techMySQL},
+	{EcosystemNPM, "redis", techRedis}, {EcosystemNPM, "ioredis", techRedis},
+	{EcosystemNPM, "mongodb", techMongoDB}, {EcosystemNPM, "mongoose", techMongoDB},
+	{EcosystemNPM, "kafkajs", techKafka}, {EcosystemNPM, "amqplib", techRabbitMQ},
+	{EcosystemNPM, "aws-sdk", techAWS}, {EcosystemNPM, "@aws-sdk/*", techAWS},
+	{EcosystemNPM, "@google-cloud/*", techGCP}, {EcosystemNPM, "@azure/*", techAzure},
+	// PyPI
+	{EcosystemPyPI, "psycopg", techPostgres}, {EcosystemPyPI, "psycopg2", techPostgres},
+	{EcosystemPyPI, "psycopg2-binary", techPostgres}, {EcosystemPyPI, "asyncpg", techPostgres},
+	{EcosystemPyPI, "pymysql", techMySQL}, {EcosystemPyPI, "mysqlclient", techMySQL},
+	{EcosystemPyPI, "redis", techRedis}, {EcosystemPyPI, "pymongo", techMongoDB}, {EcosystemPyPI, "motor", techMongoDB},
+	{EcosystemPyPI, "kafka-python", techKafka}, {EcosystemPyPI, "confluent-kafka", techKafka}, {EcosystemPyPI, "aiokafka", techKafka},
+	{EcosystemPyPI, "pika", techRabbitMQ},
+	{EcosystemPyPI, "boto3", techAWS}, {EcosystemPyPI, "google-cloud-*", techGCP}, {EcosystemPyPI, "azure-*", techAzure},
+	// Go
+	{EcosystemGo, "github.com/jackc/pgx*", techPostgres}, {EcosystemGo, "github.com/lib/pq", techPostgres},
+	{EcosystemGo, "github.com/go-sql-driver/mysql", techMySQL},
+	{EcosystemGo, "github.com/redis/go-redis*", techRedis}, {EcosystemGo, "github.com/go-redis/redis*", techRedis},
+	{EcosystemGo, "go.mongodb.org/mongo-driver*", techMongoDB},
+	{EcosystemGo, "github.com/segmentio/kafka-go", techKafka}, {EcosystemGo, "github.com/IBM/sarama", techKafka},
+	{EcosystemGo, "github.com/confluentinc/confluent-kafka-go*", techKafka},
+	{EcosystemGo, "github.com/rabbitmq/amqp091-go", techRabbitMQ},
+	{EcosystemGo, "github.com/aws/aws-sdk-go*", techAWS}, {EcosystemGo, "cloud.google.com/go*", techGCP},
+	{EcosystemGo, "github.com/Azure/azure-sdk-for-go*", techAzure},
+	// RubyGems
+	{EcosystemRubyGems, "pg", techPostgres}, {EcosystemRubyGems, "mysql2", techMySQL},
+	{EcosystemRubyGems, "redis", techRedis}, {EcosystemRubyGems, "mongoid", techMongoDB},
+	{EcosystemRubyGems, "ruby-kafka", techKafka}, {EcosystemRubyGems, "bunny", techRabbitMQ},
+	{EcosystemRubyGems, "aws-sdk*", techAWS}, {EcosystemRubyGems, "google-cloud-*", techGCP}, {EcosystemRubyGems, "azure-*", techAzure},
+	// Composer
+	{EcosystemComposer, "predis/predis", techRedis}, {EcosystemComposer, "mongodb/mongodb", techMongoDB},
+	{EcosystemComposer, "php-amqplib/php-amqplib", techRabbitMQ},
+	{EcosystemComposer, "aws/aws-sdk-php", techAWS}, {EcosystemComposer, "google/cloud*", techGCP},
+	// Cargo
+	{EcosystemCargo, "tokio-postgres", techPostgres}, {EcosystemCargo, "postgres", techPostgres},
+	{EcosystemCargo, "mysql", techMySQL}, {EcosystemCargo, "redis", techRedis}, {EcosystemCargo, "mongodb", techMongoDB},
+	{EcosystemCargo, "rdkafka", techKafka}, {EcosystemCargo, "lapin", techRabbitMQ},
+	{EcosystemCargo, "aws-sdk-*", techAWS}, {EcosystemCargo, "aws-config", techAWS},
+	// Maven
+	{EcosystemMaven, "org.postgresql:postgresql", techPostgres},
+	{EcosystemMaven, "com.mysql:mysql-connector-j", techMySQL}, {EcosystemMaven, "mysql:mysql-connector-java", techMySQL},
+	{EcosystemMaven, "redis.clients:jedis", techRedis}, {EcosystemMaven, "io.lettuce:lettuce-core", techRedis},
+	{EcosystemMaven, "org.mongodb:*", techMongoDB},
+	{EcosystemMaven, "org.apache.kafka:*", techKafka}, {EcosystemMaven, "org.springframework.kafka:*", techKafka},
+	{EcosystemMaven, "com.rabbitmq:amqp-client", techRabbitMQ},
+	{EcosystemMaven, "software.amazon.awssdk:*", techAWS}, {EcosystemMaven, "com.amazonaws:*", techAWS},
+	{EcosystemMaven, "com.google.cloud:*", techGCP}, {EcosystemMaven, "com.azure:*", techAzure},
+}
+
+// imageRules map container image names (without registry or tag) to services.
+var imageRules = []struct {
+	Pattern *regexp.Regexp
+	Tech    Tech
+}{
+	{regexp.MustCompile(`(^|/)(postgres|postgis|timescaledb[\w-]*|postgresql)$`), techPostgres},
+	{regexp.MustCompile(`(^|/)(mysql|mariadb|percona)$`), techMySQL},
+	{regexp.MustCompile(`(^|/)(redis|redis-stack[\w-]*|valkey|keydb)$`), techRedis},
+	{regexp.MustCompile(`(^|/)mongo(db)?$`), techMongoDB},
+	{regexp.MustCompile(`(^|/)(kafka|cp-kafka|cp-server|redpanda)$`), techKafka},
+	{regexp.MustCompile(`(^|/)rabbitmq$`), techRabbitMQ},
+	{regexp.MustCompile(`(^|/)localstack$`), techAWS},
+}
+
+// contentRule detects a service from a pattern in files matching a glob
+// relative to the package directory.
+type contentRule struct {
+	Globs   []string
+	Pattern *regexp.Regexp
+	Tech    Tech
+}
+
+var (
+	// ORM and framework database configuration
+	ormRules = []contentRule{
+		{[]string{"prisma/schema.prisma", "schema.prisma"}, regexp.MustCompile(`provider\s*=\s*"(postgresql|postgres)"`), techPostgres},
+		{[]string{"prisma/schema.prisma", "schema.prisma"}, regexp.MustCompile(`provider\s*=\s*"mysql"`), techMySQL},
+		{[]string{"prisma/schema.prisma", "schema.prisma"}, regexp.MustCompile(`provider\s*=\s*"mongodb"`), techMongoDB},
+		{[]string{"drizzle.config.*"}, regexp.MustCompile(`(dialect|driver)\s*:\s*["'](postgresql|pg)["']`), techPostgres},
+		{[]string{"drizzle.config.*"}, regexp.MustCompile(`(dialect|driver)\s*:\s*["'](mysql|mysql2)["']`), techMySQL},
+		{[]string{"ormconfig.*", "data-source.*", "src/data-source.*"}, regexp.MustCompile(`type["']?\s*:\s*["'](postgres)["']`), techPostgres},
+		{[]string{"ormconfig.*", "data-source.*", "src/data-source.*"}, regexp.MustCompile(`type["']?\s*:\s*["'](mysql|mariadb)["']`), techMySQL},
+		{[]string{"ormconfig.*", "data-source.*", "src/data-source.*"}, regexp.MustCompile(`type["']?\s*:\s*["']mongodb["']`), techMongoDB},
+		{[]string{"config/config.json"}, regexp.MustCompile(`"dialect"\s*:\s*"postgres"`), techPostgres},
+		{[]string{"config/config.json"}, regexp.MustCompile(`"dialect"\s*:\s*"(mysql|mariadb)"`), techMySQL},
+		{[]string{"config/database.yml"}, regexp.MustCompile(`adapter:\s*(postgresql|postgis)`), techPostgres},
+		{[]string{"config/database.yml"}, regexp.MustCompile(`adapter:\s*(mysql2|trilogy)`), techMySQL},
+		{[]string{"*/settings.py", "*/settings/*.py", "settings.py"}, regexp.MustCompile(`django\.db\.backends\.postgresql`), techPostgres},
+		{[]string{"*/settings.py", "*/settings/*.py", "settings.py"}, regexp.MustCompile(`django\.db\.backends\.mysql`), techMySQL},
+		{[]string{"alembic.ini"}, regexp.MustCompile(`sqlalchemy\.url\s*=\s*postgres`), techPostgres},
+		{[]string{"alembic.ini"}, regexp.MustCompile(`sqlalchemy\.url\s*=\s*mysql`), techMySQL},
+	}
+
+	// Example environment files; the real .env is never read
+	envFiles = []string{".env.example", ".env.sample", ".env.template", ".env.dist", "example.env"}
+	envRules = []contentRule{
+		{envFiles, regexp.MustCompile(`(?m)postgres(ql)?(\+\w+)?://`), techPostgres},
+		{envFiles, regexp.MustCompile(`(?m)mysql(\+\w+)?://`), techMySQL},
+		{envFiles, regexp.MustCompile(`(?m)rediss?://`), techRedis},
+		{envFiles, regexp.MustCompile(`(?m)mongodb(\+srv)?://`), techMongoDB},
+		{envFiles, regexp.MustCompile(`(?m)^\s*(export\s+)?KAFKA_\w+\s*=`), techKafka},
+		{envFiles, regexp.MustCompile(`(?m)amqps?://`), techRabbitMQ},
+		{envFiles, regexp.MustCompile(`(?m)^\s*(export\s+)?AWS_\w+\s*=`), techAWS},
+		{envFiles, regexp.MustCompile(`(?m)^\s*(export\s+)?(GCP_\w+|GOOGLE_CLOUD_PROJECT|GOOGLE_APPLICATION_CREDENTIALS)\s*=`), techGCP},
+		{envFiles, regexp.MustCompile(`(?m)^\s*(export\s+)?AZURE_\w+\s*=`), techAzure},
+	}
+
+	// Terraform providers and managed service resources
+	terraformRules = []struct {
+		Pattern *regexp.Regexp
+		Tech    Tech
+	}{
+		{regexp.MustCompile(`provider\s+"aws"|source\s*=\s*"hashicorp/aws"`), techAWS},
+		{regexp.MustCompile(`provider\s+"google(-beta)?"|source\s*=\s*"hashicorp/google(-beta)?"`), techGCP},
+		{regexp.MustCompile(`provider\s+"azurerm"|source\s*=\s*"hashicorp/azurerm"`), techAzure},
+		{regexp.MustCompile(`engine\s*=\s*"(aurora-)?postgres(ql)?"|resource\s+"(azurerm_postgresql\w*|google_sql_database_instance)"[^{]*\{[^}]*POSTGRES`), techPostgres},
+		{regexp.MustCompile(`engine\s*=\s*"(aurora-)?(mysql|mariadb)"|resource\s+"azurerm_mysql\w*"`), techMySQL},
+		{regexp.MustCompile(`resource\s+"(aws_elasticache\w*|google_redis_instance|azurerm_redis_cache)"`), techRedis},
+		{regexp.MustCompile(`resource\s+"(aws_docdb\w*|mongodbatlas_\w+)"|azurerm_cosmosdb_mongo`), techMongoDB},
+		{regexp.MustCompile(`resource\s+"(aws_msk_cluster|confluent_kafka_cluster)"`), techKafka},
+		{regexp.MustCompile(`resource\s+"aws_mq_broker"`), techRabbitMQ},
+	}
+
+	// Helm chart dependencies and values sections for bundled services
+	helmNames = map[string]Tech{
+		"postgresql": techPostgres, "postgres": techPostgres, "postgresql-ha": techPostgres,
+		"mysql": techMySQL, "mariadb": techMySQL,
+		"redis": techRedis, "redis-cluster": techRedis, "valkey": techRedis,
+		"mongodb": techMongoDB, "mongodb-sharded": techMongoDB,
+		"kafka": techKafka, "rabbitmq": techRabbitMQ,
+	}
+)
+
+// composeFiles are docker-compose file names checked for service images.
+var composeFiles = []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml", "docker-compose.override.yml"}
+
+// iacDirs hold infrastructure code at the repository root.
+var iacDirs = []string{".", "infra", "terraform", "deploy", "iac", "infrastructure"}
+
+// helmDirs hold Helm charts at the repository root.
+var helmDirs = []string{"helm", "charts", "chart", "deploy/helm", "deploy/charts"}
+
+// detectServices finds databases, brokers and cloud providers from compose
+// images, ORM configs and example env files in dir, plus Terraform and Helm
+// under the repository root when root is true.
+func detectServices(d *detection, dir string, root bool) {
+	for _, f := range composeFiles {
+		composeServices(d, filepath.Join(dir, f))
+	}
+	for _, rules := range [][]contentRule{ormRules, envRules} {
+		applyContentRules(d, dir, rules, "no known database, broker or cloud provider configured")
+	}
+
+	if !root {
+		return
+	}
+	for _, sub := range iacDirs {
+		terraformServices(d, filepath.Join(dir, sub))
+	}
+	for _, sub := range helmDirs {
+		helmServices(d, filepath.Join(dir, filepath.FromSlash(sub)))
+	}
+}
+
+// matchFiles returns the files matching the rule's globs whose content
+// matches its pattern, with the matched text of each.
+func (r contentRule) matchFiles(dir string) (files, matched []string) {
+	for _, f := range r.candidates(dir) {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if m := r.Pattern.Find(data); m != nil {
+			files = append(files, f)
+			matched = append(matched, strings.TrimSpace(string(m)))
+		}
+	}
+	return files, matched
+}
+
+// candidates returns the existing files matching the rule's globs.
+func (r contentRule) candidates(dir string) []string {
+	var files []string
+	for _, g := range r.Globs {
+		matches, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(g)))
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// applyContentRules adds every rule's matches and reports candidate files
+// none of the rules matched as near misses.
+func applyContentRules(d *detection, dir string, rules []contentRule, missReason string) {
+	seen := make(map[string]bool)
+	hit := make(map[string]bool)
+	var candidates []string
+	for _, r := range rules {
+		for _, f := range r.candidates(dir) {
+			if !seen[f] {
+				seen[f] = true
+				candidates = append(candidates, f)
+			}
+		}
+		files, matched := r.matchFiles(dir)
+		for i, f := range files {
+			hit[f] = true
+			d.add(r.Tech, f, fmt.Sprintf("matches %q", matched[i]))
+		}
+	}
+	for _, f := range candidates {
+		if !hit[f] {
+			d.miss(f, missReason)
+		}
+	}
+}
+
+// composeServices adds services whose images appear in a compose file.
+// Numeric image tags become the service version, e.g. postgres:16 → "16".
+func composeServices(d *detection, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	if yaml.Unmarshal(data, &compose) != nil {
+		d.miss(path, "not valid compose YAML")
+		return
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, svc := range names {
+		image := compose.Services[svc].Image
+		if image == "" {
+			continue
+		}
+		name, tag := splitImage(image)
+		known := false
+		for _, r := range imageRules {
+			if r.Pattern.MatchString(name) {
+				t := r.Tech
+				if v := imageTagVersion(tag); v != "" && t.Category != "cloud" {
+					t.Version = v
+				}
+				d.add(t, path, fmt.Sprintf("service %s image %s", svc, image))
+				known = true
+			}
+		}
+		if !known {
+			d.miss(path, fmt.Sprintf("service %s image %s is not a known service", svc, image))
+		}
+	}
+}
+
+// splitImage splits "registry:5000/org/name:tag@sha256:…" into the
+// lowercased repository path and tag.
+func splitImage(image string) (string, string) {
+	image, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(image)), "@")
+	var tag string
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	return image, tag
+}
+
+var imageTag = regexp.MustCompile(`^v?(\d+(\.\d+){0,2})`)
+
+func imageTagVersion(tag string) string {
+	if m := imageTag.FindStringSubmatch(tag); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// terraformServices scans *.tf files directly in dir.
+func terraformServices(d *detection, dir string) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		known := false
+		for _, r := range terraformRules {
+			if m := r.Pattern.Find(data); m != nil {
+				d.add(r.Tech, f, fmt.Sprintf("matches %q", string(m)))
+				known = true
+			}
+		}
+		if !known && bytes.Contains(data, []byte("provider")) {
+			d.miss(f, "no known cloud provider or managed service")
+		}
+	}
+}
+
+// helmServices reads Chart.yaml dependencies and enabled values sections of
+// every chart directly under dir (or dir itself when it is a chart).
+func helmServices(d *detection, dir string) {
+	charts, _ := filepath.Glob(filepath.Join(dir, "*", "Chart.yaml"))
+	if _, err := os.Stat(filepath.Join(dir, "Chart.yaml")); err == nil {
+		charts = append(charts, filepath.Join(dir, "Chart.yaml"))
+	}
+
+	for _, chart := range charts {
+		chartDir := filepath.Dir(chart)
+
+		if data, err := os.ReadFile(chart); err == nil {
+			var c struct {
+				Dependencies []struct {
+					Name string `yaml:"name"`
+				} `yaml:"dependencies"`
+			}
+			if yaml.Unmarshal(data, &c) == nil {
+				for _, dep := range c.Dependencies {
+					if t, ok := helmNames[dep.Name]; ok {
+						d.add(t, chart, "chart dependency "+dep.Name)
+					} else {
+						d.miss(chart, fmt.Sprintf("chart dependency %s is not a known service", dep.Name))
+					}
+				}
+			}
+		}
+
+		values, _ := filepath.Glob(filepath.Join(chartDir, "values*.yaml"))
+		for _, v := range values {
+			data, err := os.ReadFile(v)
+			if err != nil {
+				continue
+			}
+			var sections map[string]any
+			if yaml.Unmarshal(data, &sections) != nil {
+				continue
+			}
+			keys := make([]string, 0, len(sections))
+			for key := range sections {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				t, ok := helmNames[key]
+				if !ok {
+					continue
+				}
+				// Subchart sections are commonly toggled with `enabled`
+				if m, isMap := sections[key].(map[string]any); isMap {
+					if enabled, set := m["enabled"].(bool); set && !enabled {
+						d.miss(v, fmt.Sprintf("values section %s is disabled", key))
+						continue
+					}
+				}
+				d.add(t, v, "values section "+key)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlTable is a parsed TOML table. Strings stay strings, other scalars are
+// rendered as text, arrays are []any and nested tables are tomlTable.
+type tomlTable map[string]any
+
+// tomlDoc indexes a TOML document by table. Every table is listed under its
+// dotted name, including tables nested in others or created by dotted keys,
+// so `[dependencies.serde]` and `serde.workspace = true` both appear as the
+// "serde" entry of Tables["dependencies"] and as Tables["dependencies.serde"].
+type tomlDoc struct {
+	Tables map[string]tomlTable   // by dotted name, "" for the root
+	Arrays map[string][]tomlTable // [[array]] tables by dotted name
+}
+
+// str returns a string value or "".
+func (t tomlTable) str(key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// list returns an array of strings, or nil.
+func (t tomlTable) list(key string) []string {
+	arr, _ := t[key].([]any)
+	var out []string
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parseTOML parses a manifest or lockfile. A document that is not valid
+// TOML yields no tables.
+func parseTOML(content string) tomlDoc {
+	doc := tomlDoc{
+		Tables: map[string]tomlTable{},
+		Arrays: map[string][]tomlTable{},
+	}
+	var raw map[string]any
+	if _, err := toml.Decode(content, &raw); err != nil {
+		raw = nil
+	}
+	root, _ := tomlValue(raw).(tomlTable)
+	if root == nil {
+		root = tomlTable{}
+	}
+	doc.index("", root)
+	return doc
+}
+
+// index records t under name, then its subtables and arrays of tables.
+func (d tomlDoc) index(name string, t tomlTable) {
+	d.Tables[name] = t
+	for key, v := range t {
+		if name != "" {
+			key = name + "." + key
+		}
+		switch v := v.(type) {
+		case tomlTable:
+			d.index(key, v)
+		case []any:
+			var tables []tomlTable
+			for _, item := range v {
+				if table, ok := item.(tomlTable); ok {
+					tables = append(tables, table)
+				}
+			}
+			if len(tables) > 0 && len(tables) == len(v) {
+				d.Arrays[key] = tables
+			}
+		}
+	}
+}
+
+// tomlValue converts a decoded value to the tomlTable representation.
+func tomlValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		t := make(tomlTable, len(v))
+		for k, item := range v {
+			t[k] = tomlValue(item)
+		}
+		return t
+	case []map[string]any:
+		arr := make([]any, len(v))
+		for i, item := range v {
+			arr[i] = tomlValue(item)
+		}
+		return arr
+	case []any:
+		arr := make([]any, len(v))
+		for i, item := range v {
+			arr[i] = tomlValue(item)
+		}
+		return arr
+	case string:
+		return v
+	case nil:
+		return nil
+	}
+	return fmt.Sprint(v)
+}
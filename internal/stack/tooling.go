@@ -0,0 +1,303 @@
+package stack
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Test frameworks, linters/formatters and CI systems.
+var (
+	techJest       = Tech{Name: "jest", Category: "test"}
+	techVitest     = Tech{Name: "vitest", Category: "test"}
+	techPytest     = Tech{Name: "pytest", Category: "test"}
+	techGoTest     = Tech{Name: "go-test", Category: "test"}
+	techRSpec      = Tech{Name: "rspec", Category: "test"}
+	techMinitest   = Tech{Name: "minitest", Category: "test"}
+	techJUnit      = Tech{Name: "junit", Category: "test"}
+	techPHPUnit    = Tech{Name: "phpunit", Category: "test"}
+	techPest       = Tech{Name: "pest", Category: "test"}
+	techESLint     = Tech{Name: "eslint", Category: "lint"}
+	techPrettier   = Tech{Name: "prettier", Category: "lint"}
+	techBiome      = Tech{Name: "biome", Category: "lint"}
+	techRuff       = Tech{Name: "ruff", Category: "lint"}
+	techBlack      = Tech{Name: "black", Category: "lint"}
+	techFlake8     = Tech{Name: "flake8", Category: "lint"}
+	techMypy       = Tech{Name: "mypy", Category: "lint"}
+	techGolangci   = Tech{Name: "golangci-lint", Category: "lint"}
+	techRuboCop    = Tech{Name: "rubocop", Category: "lint"}
+	techStandardRB = Tech{Name: "standardrb", Category: "lint"}
+	techRustfmt    = Tech{Name: "rustfmt", Category: "lint"}
+	techClippy     = Tech{Name: "clippy", Category: "lint"}
+	techPHPStan    = Tech{Name: "phpstan", Category: "lint"}
+	techPHPCSFixer = Tech{Name: "php-cs-fixer", Category: "lint"}
+)
+
+// toolingDependencyRules detect test frameworks and linters from dev dependencies.
+var toolingDependencyRules = []dependencyRule{
+	{EcosystemNPM, "jest", techJest}, {EcosystemNPM, "vitest", techVitest},
+	{EcosystemNPM, "eslint", techESLint}, {EcosystemNPM, "prettier", techPrettier},
+	{EcosystemNPM, "@biomejs/biome", techBiome},
+	{EcosystemPyPI, "pytest", techPytest}, {EcosystemPyPI, "ruff", techRuff}, {EcosystemPyPI, "black", techBlack},
+	{EcosystemPyPI, "flake8", techFlake8}, {EcosystemPyPI, "mypy", techMypy},
+	{EcosystemRubyGems, "rspec", techRSpec}, {EcosystemRubyGems, "rspec-rails", techRSpec},
+	{EcosystemRubyGems, "minitest", techMinitest},
+	{EcosystemRubyGems, "rubocop", techRuboCop}, {EcosystemRubyGems, "standard", techStandardRB},
+	{EcosystemComposer, "phpunit/phpunit", techPHPUnit}, {EcosystemComposer, "pestphp/pest", techPest},
+	{EcosystemComposer, "phpstan/phpstan", techPHPStan}, {EcosystemComposer, "friendsofphp/php-cs-fixer", techPHPCSFixer},
+	{EcosystemMaven, "org.junit.jupiter:*", techJUnit}, {EcosystemMaven, "junit:junit", techJUnit},
+}
+
+// toolingFiles map config file globs to the tool they configure.
+var toolingFiles = []struct {
+	Glob string
+	Tech Tech
+}{
+	{"jest.config.*", techJest}, {"vitest.config.*", techVitest},
+	{"pytest.ini", techPytest}, {"conftest.py", techPytest}, {"tests/conftest.py", techPytest},
+	{".rspec", techRSpec}, {"phpunit.xml*", techPHPUnit},
+	{".eslintrc*", techESLint}, {"eslint.config.*", techESLint},
+	{".prettierrc*", techPrettier}, {"prettier.config.*", techPrettier},
+	{"biome.json*", techBiome},
+	{"ruff.toml", techRuff}, {".ruff.toml", techRuff}, {".flake8", techFlake8}, {"mypy.ini", techMypy},
+	{".golangci.y*ml", techGolangci}, {".golangci.toml", techGolangci}, {".golangci.json", techGolangci},
+	{".rubocop.yml", techRuboCop}, {".standard.yml", techStandardRB},
+	{"rustfmt.toml", techRustfmt}, {".rustfmt.toml", techRustfmt}, {"clippy.toml", techClippy},
+	{"phpstan.neon*", techPHPStan}, {".php-cs-fixer*.php", techPHPCSFixer},
+}
+
+// toolingContentRules find tools configured inside shared config files.
+var toolingContentRules = []contentRule{
+	{[]string{"pyproject.toml"}, regexp.MustCompile(`(?m)^\[tool\.pytest`), techPytest},
+	{[]string{"setup.cfg", "tox.ini"}, regexp.MustCompile(`(?m)^\[(tool:)?pytest\]`), techPytest},
+	{[]string{"pyproject.toml"}, regexp.MustCompile(`(?m)^\[tool\.ruff`), techRuff},
+	{[]string{"pyproject.toml"}, regexp.MustCompile(`(?m)^\[tool\.black\]`), techBlack},
+	{[]string{"pyproject.toml"}, regexp.MustCompile(`(?m)^\[tool\.mypy\]`), techMypy},
+	{[]string{"setup.cfg", "tox.ini"}, regexp.MustCompile(`(?m)^\[flake8\]`), techFlake8},
+	{[]string{"build.gradle", "build.gradle.kts"}, regexp.MustCompile(`junit`), techJUnit},
+}
+
+// ciFiles map files and directories at the repository root to CI systems.
+var ciFiles = []struct {
+	Path string
+	Tech Tech
+}{
+	{".github/workflows", Tech{Name: "github-actions", Category: "ci"}},
+	{".gitlab-ci.yml", Tech{Name: "gitlab-ci", Category: "ci"}},
+	{".circleci/config.yml", Tech{Name: "circleci", Category: "ci"}},
+	{"Jenkinsfile", Tech{Name: "jenkins", Category: "ci"}},
+	{"azure-pipelines.yml", Tech{Name: "azure-pipelines", Category: "ci"}},
+	{"bitbucket-pipelines.yml", Tech{Name: "bitbucket-pipelines", Category: "ci"}},
+}
+
+// detectTooling finds test frameworks and linters configured in dir, plus
+// CI systems when dir is the repository root.
+func detectTooling(d *detection, dir string, root bool) {
+	for _, f := range toolingFiles {
+		matches, _ := filepath.Glob(filepath.Join(dir, f.Glob))
+		for _, m := range matches {
+			d.add(f.Tech, m, "config file "+f.Glob)
+		}
+	}
+	applyContentRules(d, dir, toolingContentRules, "no known test framework or linter configured")
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		d.add(techGoTest, filepath.Join(dir, "go.mod"), "Go module (go test is built in)")
+	}
+
+	if !root {
+		return
+	}
+	for _, ci := range ciFiles {
+		path := filepath.Join(dir, filepath.FromSlash(ci.Path))
+		if _, err := os.Stat(path); err == nil {
+			d.add(ci.Tech, path, "CI config")
+		}
+	}
+}
+
+// Commands are the canonical commands for a package, run from its directory.
+// Empty fields are unknown.
+type Commands struct {
+	Test  string `json:"test,omitempty"`
+	Lint  string `json:"lint,omitempty"`
+	Build string `json:"build,omitempty"`
+}
+
+// IsEmpty reports whether no command is known.
+func (c Commands) IsEmpty() bool {
+	return c == Commands{}
+}
+
+var makeTarget = regexp.MustCompile(`(?m)^(test|lint|build)\s*:`)
+
+// npmPlaceholderTest is the test script `npm init` writes.
+const npmPlaceholderTest = `echo "Error: no test specified" && exit 1`
+
+// detectCommands works out how to test, lint and build the package in dir
+// (relative to the project root). Makefile targets win, then package.json
+// scripts run with the project's package manager, then each stack's
+// conventional command.
+func detectCommands(projectRoot, rel string, techs []Tech) Commands {
+	dir := filepath.Join(projectRoot, filepath.FromSlash(rel))
+	var cmds Commands
+	set := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "Makefile")); err == nil {
+		for _, m := range makeTarget.FindAllSubmatch(data, -1) {
+			switch target := string(m[1]); target {
+			case "test":
+				set(&cmds.Test, "make test")
+			case "lint":
+				set(&cmds.Lint, "make lint")
+			case "build":
+				set(&cmds.Build, "make build")
+			}
+		}
+	}
+
+	if scripts := packageScripts(dir); scripts != nil {
+		pm := packageManager(projectRoot, rel)
+		if s := scripts["test"]; s != "" && s != npmPlaceholderTest {
+			set(&cmds.Test, runScript(pm, "test"))
+		}
+		if scripts["lint"] != "" {
+			set(&cmds.Lint, runScript(pm, "lint"))
+		}
+		if scripts["build"] != "" {
+			set(&cmds.Build, runScript(pm, "build"))
+		}
+	}
+
+	has := make(map[string]bool)
+	for _, t := range techs {
+		has[t.Name] = true
+	}
+	gradle := "gradle"
+	if _, err := os.Stat(filepath.Join(dir, "gradlew")); err == nil {
+		gradle = "./gradlew"
+	}
+	_, gradleErr := os.Stat(filepath.Join(dir, "build.gradle"))
+	_, gradleKtsErr := os.Stat(filepath.Join(dir, "build.gradle.kts"))
+	isGradle := gradleErr == nil || gradleKtsErr == nil
+
+	switch {
+	case has["go"]:
+		set(&cmds.Test, "go test ./...")
+		if has["golangci-lint"] {
+			set(&cmds.Lint, "golangci-lint run")
+		}
+		set(&cmds.Lint, "go vet ./...")
+		set(&cmds.Build, "go build ./...")
+	case has["rust"]:
+		set(&cmds.Test, "cargo test")
+		set(&cmds.Lint, "cargo clippy")
+		set(&cmds.Build, "cargo build")
+	case has["python"]:
+		if has["pytest"] {
+			set(&cmds.Test, "pytest")
+		}
+		switch {
+		case has["ruff"]:
+			set(&cmds.Lint, "ruff check .")
+		case has["flake8"]:
+			set(&cmds.Lint, "flake8")
+		}
+	case has["ruby"]:
+		switch {
+		case has["rspec"]:
+			set(&cmds.Test, "bundle exec rspec")
+		case has["rails"]:
+			// Rails apps without RSpec use Minitest through the rails runner
+			set(&cmds.Test, "bin/rails test")
+		case has["minitest"]:
+			set(&cmds.Test, "bundle exec rake test")
+		}
+		switch {
+		case has["rubocop"]:
+			set(&cmds.Lint, "bundle exec rubocop")
+		case has["standardrb"]:
+			set(&cmds.Lint, "bundle exec standardrb")
+		}
+	case has["node"]:
+		switch {
+		case has["vitest"]:
+			set(&cmds.Test, "npx vitest run")
+		case has["jest"]:
+			set(&cmds.Test, "npx jest")
+		}
+		set(&cmds.Test, "npm test")
+		switch {
+		case has["biome"]:
+			set(&cmds.Lint, "npx biome check .")
+		case has["eslint"]:
+			set(&cmds.Lint, "npx eslint .")
+		}
+	case has["java"], has["kotlin"]:
+		if isGradle {
+			set(&cmds.Test, gradle+" test")
+			set(&cmds.Lint, gradle+" check")
+			set(&cmds.Build, gradle+" build")
+		}
+		set(&cmds.Test, "mvn test")
+		set(&cmds.Build, "mvn package")
+	case has["php"]:
+		if has["pest"] {
+			set(&cmds.Test, "vendor/bin/pest")
+		}
+		set(&cmds.Test, "vendor/bin/phpunit")
+		if has["phpstan"] {
+			set(&cmds.Lint, "vendor/bin/phpstan analyse")
+		}
+	}
+	return cmds
+}
+
+// packageScripts returns the scripts of dir/package.json, or nil.
+func packageScripts(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || pkg.Scripts == nil {
+		return nil
+	}
+	return pkg.Scripts
+}
+
+// packageManager returns the JavaScript package manager whose lockfile is
+// nearest to the package, walking up to the project root.
+func packageManager(projectRoot, rel string) string {
+	for dir := rel; ; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		abs := filepath.Join(projectRoot, filepath.FromSlash(dir))
+		for _, lock := range []struct{ File, PM string }{
+			{"pnpm-lock.yaml", "pnpm"}, {"yarn.lock", "yarn"}, {"bun.lockb", "bun"}, {"bun.lock", "bun"}, {"package-lock.json", "npm"},
+		} {
+			if _, err := os.Stat(filepath.Join(abs, lock.File)); err == nil {
+				return lock.PM
+			}
+		}
+		if dir == "." {
+			return "npm"
+		}
+	}
+}
+
+// runScript returns the command running a package.json script.
+func runScript(pm, script string) string {
+	switch {
+	case pm == "npm" && script == "test":
+		return "npm test"
+	case pm == "npm", pm == "bun":
+		// `bun test` is Bun's own runner, not the script
+		return pm + " run " + script
+	}
+	return pm + " " + script
+}
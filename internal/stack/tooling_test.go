@@ -0,0 +1,76 @@
+package stack
+
+import "testing"
+
+func TestDetectCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Commands
+	}{
+		{
+			name:  "go",
+			files: map[string]string{"go.mod": "module m\n", ".golangci.yml": ""},
+			want:  Commands{Test: "go test ./...", Lint: "golangci-lint run", Build: "go build ./..."},
+		},
+		{
+			name:  "python without a test runner",
+			files: map[string]string{"requirements.txt": "flask==3.0.0\n", "ruff.toml": ""},
+			want:  Commands{Lint: "ruff check ."},
+		},
+		{
+			name:  "python with pytest",
+			files: map[string]string{"pyproject.toml": "[project]\nname = \"svc\"\n\n[tool.pytest.ini_options]\naddopts = \"-q\"\n"},
+			want:  Commands{Test: "pytest"},
+		},
+		{
+			name:  "ruby with rspec",
+			files: map[string]string{"Gemfile": "gem \"rails\"\ngem \"rspec-rails\"\ngem \"rubocop\"\n"},
+			want:  Commands{Test: "bundle exec rspec", Lint: "bundle exec rubocop"},
+		},
+		{
+			name:  "rails with minitest",
+			files: map[string]string{"Gemfile": "gem \"rails\"\n"},
+			want:  Commands{Test: "bin/rails test"},
+		},
+		{
+			name:  "ruby with minitest",
+			files: map[string]string{"Gemfile": "gem \"sinatra\"\ngem \"minitest\"\n"},
+			want:  Commands{Test: "bundle exec rake test"},
+		},
+		{
+			name:  "ruby without a test runner",
+			files: map[string]string{"Gemfile": "gem \"sinatra\"\n", ".standard.yml": ""},
+			want:  Commands{Lint: "bundle exec standardrb"},
+		},
+		{
+			name: "node scripts with pnpm",
+			files: map[string]string{
+				"package.json":   `{"scripts": {"test": "vitest", "lint": "eslint ."}, "devDependencies": {"vitest": "1"}}`,
+				"pnpm-lock.yaml": "",
+			},
+			want: Commands{Test: "pnpm test", Lint: "pnpm lint"},
+		},
+		{
+			name:  "node placeholder test script",
+			files: map[string]string{"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}, "devDependencies": {"jest": "29"}}`},
+			want:  Commands{Test: "npx jest"},
+		},
+		{
+			name: "makefile wins",
+			files: map[string]string{
+				"go.mod":   "module m\n",
+				"Makefile": "test:\n\tgo test -race ./...\n\nlint :\n\tstaticcheck ./...\n",
+			},
+			want: Commands{Test: "make test", Lint: "make lint", Build: "go build ./..."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packages := DetectPackages(writeTree(t, tt.files))
+			if got := packages[0].Commands; got != tt.want {
+				t.Errorf("commands = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
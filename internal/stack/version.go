@@ -0,0 +1,101 @@
+package stack
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two dotted versions numerically ("1.10" > "1.9").
+// A leading "v" is ignored and missing components count as zero, so
+// "13" == "13.0.0". Pre-release suffixes ("-rc.1") sort before the release.
+func CompareVersions(a, b string) int {
+	a, aPre := splitPrerelease(strings.TrimPrefix(strings.TrimSpace(a), "v"))
+	b, bPre := splitPrerelease(strings.TrimPrefix(strings.TrimSpace(b), "v"))
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	}
+	return 1
+}
+
+func splitPrerelease(v string) (string, string) {
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// MatchVersion reports whether version satisfies a range made of
+// space-separated comparators that must all hold, e.g. "<13",
+// ">=5", ">=13 <15". An empty range matches any version.
+func MatchVersion(version, rng string) bool {
+	for _, c := range strings.Fields(rng) {
+		op := strings.TrimRight(c, "0123456789.v")
+		want := strings.TrimPrefix(c, op)
+		cmp := CompareVersions(version, want)
+		var ok bool
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "=", "==", "":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// constraintFloor returns the lowest version a constraint allows, e.g.
+// "^14.1.0" → "14.1.0", ">=2.0,<3" → "2.0", "~> 7.1" → "7.1". It is used
+// when no lockfile pins the dependency. Returns "" if there is no lower bound.
+func constraintFloor(constraint string) string {
+	for _, part := range strings.FieldsFunc(constraint, func(r rune) bool { return r == ',' || r == '|' || r == ' ' }) {
+		part = strings.TrimLeft(part, "^~=>v")
+		if part == "" || strings.HasPrefix(part, "<") || strings.HasPrefix(part, "!") {
+			continue
+		}
+		if part[0] < '0' || part[0] > '9' {
+			continue
+		}
+		// Wildcards such as 1.x or 2.* floor to their fixed prefix
+		if i := strings.IndexAny(part, "x*X"); i > 0 {
+			part = strings.TrimSuffix(part[:i], ".")
+		}
+		return part
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package stack
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10", "1.9", 1},
+		{"13", "13.0.0", 0},
+		{"v1.9.1", "1.9.1", 0},
+		{"14.0.0-rc.1", "14.0.0", -1},
+		{"14.0.0-rc.2", "14.0.0-rc.1", 1},
+		{"2", "10", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		version, rng string
+		want         bool
+	}{
+		{"12.3.4", "<13", true},
+		{"13.0.0", "<13", false},
+		{"13.0.0", ">=13", true},
+		{"14.1.0", ">=13 <15", true},
+		{"15.0.0", ">=13 <15", false},
+		{"3.4.15", "", true},
+		{"2.7.0", "=2.7", true},
+		{"2.7.1", "!=2.7.1", false},
+		{"v5.0.0", ">4", true},
+	}
+	for _, tt := range tests {
+		if got := MatchVersion(tt.version, tt.rng); got != tt.want {
+			t.Errorf("MatchVersion(%q, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintFloor(t *testing.T) {
+	tests := map[string]string{
+		"^14.1.0":    "14.1.0",
+		"~5.3":       "5.3",
+		">=2.0,<3":   "2.0",
+		"~> 7.1":     "7.1",
+		"<3":         "",
+		"1.x":        "1",
+		"2.*":        "2",
+		"*":          "",
+		"latest":     "",
+		"v1.9.1":     "1.9.1",
+		"^1.2 || ^2": "1.2",
+	}
+	for constraint, want := range tests {
+		if got := constraintFloor(constraint); got != want {
+			t.Errorf("constraintFloor(%q) = %q, want %q", constraint, got, want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package stack
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workspace is a monorepo workspace declaration found at the project root.
+type Workspace struct {
+	Tool    string   // "pnpm", "npm", "yarn", "go", "cargo", "nx", "turborepo", "lerna"
+	File    string   // declaring file, e.g. "pnpm-workspace.yaml"
+	Members []string // member directories relative to the root
+}
+
+// DetectWorkspaces reads the workspace files at the project root.
+func DetectWorkspaces(projectRoot string) []Workspace {
+	var workspaces []Workspace
+	add := func(tool, file string, patterns []string) {
+		workspaces = append(workspaces, Workspace{
+			Tool:    tool,
+			File:    file,
+			Members: expandMembers(projectRoot, patterns),
+		})
+	}
+
+	// pnpm
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "pnpm-workspace.yaml")); err == nil {
+		var ws struct {
+			Packages []string `yaml:"packages"`
+		}
+		if yaml.Unmarshal(data, &ws) == nil {
+			add("pnpm", "pnpm-workspace.yaml", ws.Packages)
+		}
+	}
+
+	// npm / yarn: "workspaces": [...] or "workspaces": {"packages": [...]}
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "package.json")); err == nil {
+		var pkg struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && len(pkg.Workspaces) > 0 {
+			var patterns []string
+			if json.Unmarshal(pkg.Workspaces, &patterns) != nil {
+				var obj struct {
+					Packages []string `json:"packages"`
+				}
+				_ = json.Unmarshal(pkg.Workspaces, &obj)
+				patterns = obj.Packages
+			}
+			tool := "npm"
+			if _, err := os.Stat(filepath.Join(projectRoot, "yarn.lock")); err == nil {
+				tool = "yarn"
+			}
+			add(tool, "package.json", patterns)
+		}
+	}
+
+	// Go workspaces
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "go.work")); err == nil {
+		add("go", "go.work", parseGoWorkUses(string(data)))
+	}
+
+	// Cargo workspaces
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "Cargo.toml")); err == nil {
+		if members, ok := parseCargoWorkspaceMembers(string(data)); ok {
+			add("cargo", "Cargo.toml", members)
+		}
+	}
+
+	// Lerna
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "lerna.json")); err == nil {
+		var l struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(data, &l) == nil {
+			add("lerna", "lerna.json", l.Packages)
+		}
+	}
+
+	// Nx: every project.json marks a project
+	if _, err := os.Stat(filepath.Join(projectRoot, "nx.json")); err == nil {
+		var projects []string
+		WalkDirs(projectRoot, func(rel string) {
+			if rel == "." {
+				return
+			}
+			if _, err := os.Stat(filepath.Join(projectRoot, rel, "project.json")); err == nil {
+				projects = append(projects, rel)
+			}
+		})
+		workspaces = append(workspaces, Workspace{Tool: "nx", File: "nx.json", Members: projects})
+	}
+
+	// Turborepo delegates membership to the package manager workspaces
+	if _, err := os.Stat(filepath.Join(projectRoot, "turbo.json")); err == nil {
+		workspaces = append(workspaces, Workspace{Tool: "turborepo", File: "turbo.json"})
+	}
+
+	return workspaces
+}
+
+var goWorkUse = regexp.MustCompile(`(?m)^\s*use\s+(\S+)\s*$`)
+
+// parseGoWorkUses extracts `use` directives from go.work, including blocks.
+func parseGoWorkUses(content string) []string {
+	var dirs []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(stripLineComment(line, "//"))
+		switch {
+		case strings.HasPrefix(line, "use ("), line == "use(":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, strings.Trim(line, `"`))
+		default:
+			if m := goWorkUse.FindStringSubmatch(line); m != nil {
+				dirs = append(dirs, strings.Trim(m[1], `"`))
+			}
+		}
+	}
+	return dirs
+}
+
+// parseCargoWorkspaceMembers reads `members = [...]` from a [workspace] table.
+func parseCargoWorkspaceMembers(content string) ([]string, bool) {
+	doc := parseTOML(content)
+	ws, ok := doc.Tables["workspace"]
+	if !ok {
+		return nil, false
+	}
+	return ws.list("members"), true
+}
+
+// expandMembers resolves workspace globs to existing directories.
+// Patterns starting with "!" exclude matches.
+func expandMembers(projectRoot string, patterns []string) []string {
+	include := make(map[string]bool)
+	var excludes []string
+
+	for _, p := range patterns {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "./")
+		if p == "" {
+			continue
+		}
+		if strings.HasPrefix(p, "!") {
+			excludes = append(excludes, strings.TrimPrefix(strings.TrimPrefix(p, "!"), "./"))
+			continue
+		}
+		p = strings.TrimSuffix(p, "/")
+
+		if strings.Contains(p, "**") {
+			WalkDirs(projectRoot, func(rel string) {
+				if rel != "." && globMatch(p, rel) {
+					include[rel] = true
+				}
+			})
+			continue
+		}
+
+		matches, _ := filepath.Glob(filepath.Join(projectRoot, filepath.FromSlash(p)))
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				if rel, err := filepath.Rel(projectRoot, m); err == nil {
+					include[filepath.ToSlash(rel)] = true
+				}
+			}
+		}
+	}
+
+	var members []string
+	for m := range include {
+		excluded := false
+		for _, ex := range excludes {
+			if globMatch(ex, m) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded && m != "." {
+			members = append(members, m)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// stripLineComment removes a trailing line comment.
+func stripLineComment(line, marker string) string {
+	if i := strings.Index(line, marker); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
@@ -0,0 +1,113 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectWorkspaces(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Workspace
+	}{
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"pnpm-workspace.yaml":       "packages:\n  - 'apps/*'\n  - '!apps/legacy'\n  - 'libs/**'\n",
+				"apps/web/package.json":     "{}",
+				"apps/legacy/package.json":  "{}",
+				"apps/README.md":            "",
+				"libs/ui/button/index.ts":   "",
+				"libs/node_modules/x/a.txt": "",
+			},
+			want: []Workspace{{Tool: "pnpm", File: "pnpm-workspace.yaml", Members: []string{"apps/web", "libs/ui", "libs/ui/button"}}},
+		},
+		{
+			name: "npm",
+			files: map[string]string{
+				"package.json":             `{"workspaces": ["packages/*"]}`,
+				"packages/a/package.json":  "{}",
+				"packages/b/package.json":  "{}",
+				"packages/not-a-dir.json":  "{}",
+				"elsewhere/c/package.json": "{}",
+			},
+			want: []Workspace{{Tool: "npm", File: "package.json", Members: []string{"packages/a", "packages/b"}}},
+		},
+		{
+			name: "yarn",
+			files: map[string]string{
+				"package.json":              `{"workspaces": {"packages": ["./services/*"], "nohoist": ["**/x"]}}`,
+				"yarn.lock":                 "",
+				"services/api/package.json": "{}",
+			},
+			want: []Workspace{{Tool: "yarn", File: "package.json", Members: []string{"services/api"}}},
+		},
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work":       "go 1.22\n\nuse ./tools // linters\n\nuse (\n\t./api\n\t\"./worker\"\n\t./missing\n)\n",
+				"api/go.mod":    "module api\n",
+				"worker/go.mod": "module worker\n",
+				"tools/go.mod":  "module tools\n",
+				"unused/go.mod": "module unused\n",
+			},
+			want: []Workspace{{Tool: "go", File: "go.work", Members: []string{"api", "tools", "worker"}}},
+		},
+		{
+			name: "cargo",
+			files: map[string]string{
+				"Cargo.toml":             "[workspace]\nmembers = [\n  \"crates/*\",\n  \"cli\",\n]\nexclude = [\"crates/old\"]\n",
+				"crates/core/Cargo.toml": "",
+				"crates/http/Cargo.toml": "",
+				"cli/Cargo.toml":         "",
+			},
+			want: []Workspace{{Tool: "cargo", File: "Cargo.toml", Members: []string{"cli", "crates/core", "crates/http"}}},
+		},
+		{
+			name: "cargo package without workspace",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"solo\"\n",
+			},
+		},
+		{
+			name: "lerna",
+			files: map[string]string{
+				"lerna.json":           `{"packages": ["modules/*"]}`,
+				"modules/one/index.js": "",
+				"modules/two/index.js": "",
+			},
+			want: []Workspace{{Tool: "lerna", File: "lerna.json", Members: []string{"modules/one", "modules/two"}}},
+		},
+		{
+			name: "nx and turbo",
+			files: map[string]string{
+				"nx.json":                            "{}",
+				"turbo.json":                         "{}",
+				"apps/shop/project.json":             "{}",
+				"libs/data/project.json":             "{}",
+				"libs/data/src/index.ts":             "",
+				"node_modules/@nx/core/project.json": "{}",
+			},
+			want: []Workspace{
+				{Tool: "nx", File: "nx.json", Members: []string{"apps/shop", "libs/data"}},
+				{Tool: "turborepo", File: "turbo.json"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectWorkspaces(writeTree(t, tt.files))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectWorkspaces =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGoWorkUses(t *testing.T) {
+	got := parseGoWorkUses("go 1.22\n\nuse(\n\t. // root\n\t../shared\n)\nuse \"./cmd\"\n")
+	if want := []string{".", "../shared", "./cmd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoWorkUses = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,308 @@
+package tmplvars
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/stack"
+)
+
+// ValuesFile holds project-specific template values inside .claude/.
+const ValuesFile = "ck-values.json"
+
+// Source identifies where a variable's value came from.
+const (
+	SourceFile    = "values file"
+	SourceStack   = "detected stack"
+	SourceGit     = "git"
+	SourceProject = "project"
+)
+
+// Var is a resolved template variable.
+type Var struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// Set is the collection of resolved variables, keyed by name.
+type Set map[string]Var
+
+// Values returns the plain name → value map used as template data.
+func (s Set) Values() map[string]string {
+	out := make(map[string]string, len(s))
+	for name, v := range s {
+		out[name] = v.Value
+	}
+	return out
+}
+
+// Names returns the variable names sorted alphabetically.
+func (s Set) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve collects template variables for a project. Later sources win:
+// project defaults, detected stack, git config, then the values file.
+func Resolve(projectRoot string) Set {
+	set := make(Set)
+	put := func(name, value, source string) {
+		if value != "" {
+			set[name] = Var{Name: name, Value: value, Source: source}
+		}
+	}
+
+	put("ProjectName", filepath.Base(projectRoot), SourceProject)
+	put("DefaultBranch", "main", SourceProject)
+
+	packages := stack.DetectPackages(projectRoot)
+	techs := stack.Merge(packages)
+	var names []string
+	for _, t := range techs {
+		names = append(names, t.Name)
+	}
+	put("Stack", strings.Join(names, ", "), SourceStack)
+	put("PrimaryLanguage", primaryLanguage(techs), SourceStack)
+	cmds := commands(packages)
+	put("TestCommand", cmds.Test, SourceStack)
+	put("LintCommand", cmds.Lint, SourceStack)
+	put("BuildCommand", cmds.Build, SourceStack)
+
+	put("DefaultBranch", defaultBranch(projectRoot), SourceGit)
+	put("GitUserName", gitOutput(projectRoot, "config", "user.name"), SourceGit)
+	put("GitUserEmail", gitOutput(projectRoot, "config", "user.email"), SourceGit)
+
+	values, _ := LoadFile(projectRoot)
+	for name, value := range values {
+		put(name, value, SourceFile)
+	}
+
+	return set
+}
+
+// LoadFile reads .claude/ck-values.json. A missing file yields an empty map.
+func LoadFile(projectRoot string) (map[string]string, error) {
+	data, err := os.ReadFile(valuesPath(projectRoot))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ValuesFile, err)
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ValuesFile, err)
+	}
+	return values, nil
+}
+
+// SaveValue sets a single variable in .claude/ck-values.json.
+func SaveValue(projectRoot, name, value string) error {
+	values, err := LoadFile(projectRoot)
+	if err != nil {
+		return err
+	}
+	values[name] = value
+
+	out, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling %s: %w", ValuesFile, err)
+	}
+	out = append(out, '\n')
+
+	path := valuesPath(projectRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o644)
+}
+
+// Render executes data as a text/template with the given values.
+// Referencing a variable without a value is an error.
+func Render(name string, data []byte, values map[string]string) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, fmt.Errorf("rendering template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Variables returns the top-level variables (e.g. {{.ProjectName}}) that a
+// template references, sorted and de-duplicated.
+func Variables(data []byte) ([]string, error) {
+	trees, err := parse.Parse("vars", string(data), "", "", builtinFuncs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, tree := range trees {
+		collectFields(tree.Root, seen)
+	}
+
+	vars := make([]string, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars, nil
+}
+
+// Missing returns the variables referenced by data that have no value.
+func Missing(data []byte, values map[string]string) []string {
+	vars, err := Variables(data)
+	if err != nil {
+		return nil
+	}
+	var missing []string
+	for _, v := range vars {
+		if _, ok := values[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// builtinFuncs lists text/template's predefined functions so parse accepts them.
+var builtinFuncs = map[string]any{
+	"and": nil, "call": nil, "html": nil, "index": nil, "slice": nil, "js": nil,
+	"len": nil, "not": nil, "or": nil, "print": nil, "printf": nil, "println": nil,
+	"urlquery": nil, "eq": nil, "ge": nil, "gt": nil, "le": nil, "lt": nil, "ne": nil,
+}
+
+// collectFields walks a parse tree and records the first identifier of every
+// field reference.
+func collectFields(node parse.Node, seen map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			collectFields(c, seen)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, seen)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			collectFields(c, seen)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			collectFields(a, seen)
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) > 0 {
+			seen[n.Ident[0]] = true
+		}
+	case *parse.IfNode:
+		collectBranch(&n.BranchNode, seen)
+	case *parse.RangeNode:
+		collectBranch(&n.BranchNode, seen)
+	case *parse.WithNode:
+		collectBranch(&n.BranchNode, seen)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, seen)
+	}
+}
+
+func collectBranch(b *parse.BranchNode, seen map[string]bool) {
+	collectFields(b.Pipe, seen)
+	collectFields(b.List, seen)
+	collectFields(b.ElseList, seen)
+}
+
+// primaryLanguage picks the most specific detected language.
+func primaryLanguage(techs []stack.Tech) string {
+	var langs []string
+	for _, t := range techs {
+		if t.Category == "language" {
+			langs = append(langs, t.Name)
+		}
+	}
+	// TypeScript projects also carry javascript via package.json
+	for _, l := range langs {
+		if l == "typescript" {
+			return l
+		}
+	}
+	for _, l := range langs {
+		if l != "javascript" {
+			return l
+		}
+	}
+	if len(langs) > 0 {
+		return langs[0]
+	}
+	return ""
+}
+
+// commands returns the project's test, lint and build commands: the root
+// package's, else the first package's that has one, run from its directory.
+func commands(packages []stack.Package) stack.Commands {
+	var cmds stack.Commands
+	for _, pkg := range packages {
+		prefix := ""
+		if pkg.Path != "." {
+			prefix = "cd " + pkg.Path + " && "
+		}
+		for _, f := range []struct {
+			dst *string
+			src string
+		}{
+			{&cmds.Test, pkg.Commands.Test},
+			{&cmds.Lint, pkg.Commands.Lint},
+			{&cmds.Build, pkg.Commands.Build},
+		} {
+			if *f.dst == "" && f.src != "" {
+				*f.dst = prefix + f.src
+			}
+		}
+	}
+	return cmds
+}
+
+// defaultBranch reads the remote HEAD, falling back to init.defaultBranch.
+func defaultBranch(projectRoot string) string {
+	if ref := gitOutput(projectRoot, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); ref != "" {
+		return strings.TrimPrefix(ref, "origin/")
+	}
+	return gitOutput(projectRoot, "config", "init.defaultBranch")
+}
+
+// gitOutput runs a git command in dir and returns trimmed stdout, or "".
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func valuesPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".claude", ValuesFile)
+}
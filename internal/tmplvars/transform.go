@@ -0,0 +1,55 @@
+package tmplvars
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/AdeptMind/infra-tool/claude-cli/internal/catalog"
+)
+
+// PromptFunc asks the user for the value of a missing variable.
+type PromptFunc func(name string) (string, error)
+
+// Renderer renders templated component files with the project's values.
+// Values are resolved lazily on the first templated file.
+type Renderer struct {
+	ProjectRoot string
+	Prompt      PromptFunc // nil: missing variables are an error
+
+	once   sync.Once
+	values map[string]string
+}
+
+// Transform implements catalog.Transform. Only markdown files of components
+// that set `templated: true` are rendered.
+func (r *Renderer) Transform(f *catalog.InstallFile) error {
+	if !f.Templated || !strings.HasSuffix(f.RelPath, ".md") {
+		return nil
+	}
+
+	r.once.Do(func() {
+		r.values = Resolve(r.ProjectRoot).Values()
+	})
+
+	for _, name := range Missing(f.Data, r.values) {
+		if r.Prompt == nil {
+			return fmt.Errorf("template variable %s has no value (set it with 'ck vars set %s <value>')", name, name)
+		}
+		value, err := r.Prompt(name)
+		if err != nil {
+			return err
+		}
+		r.values[name] = value
+		if err := SaveValue(r.ProjectRoot, name, value); err != nil {
+			return err
+		}
+	}
+
+	out, err := Render(f.Kind+"/"+f.Component+"/"+f.RelPath, f.Data, r.values)
+	if err != nil {
+		return err
+	}
+	f.Data = out
+	return nil
+}